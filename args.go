@@ -0,0 +1,231 @@
+package gmask
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ArgType is the type an argument of a mask is parsed into
+type ArgType int
+
+const (
+	ArgString ArgType = iota
+	ArgInt
+	ArgUint
+	ArgFloat
+	ArgBool
+)
+
+func (t ArgType) String() string {
+	switch t {
+	case ArgString:
+		return "string"
+	case ArgInt:
+		return "int"
+	case ArgUint:
+		return "uint"
+	case ArgFloat:
+		return "float"
+	case ArgBool:
+		return "bool"
+	default:
+		return "ArgType(" + strconv.Itoa(int(t)) + ")"
+	}
+}
+
+// Arg declares an argument of a mask registered with RegMaskArgsFunc,
+// arguments are positional in the order they are declared
+type Arg struct {
+	Name string
+	Type ArgType
+	// Default is used when the argument is left out or empty, it must be of
+	// the Go type matching Type: string, int, uint, float64 or bool.
+	// An argument without default is required.
+	Default any
+	// Check validates the parsed value, it is optional
+	Check func(value any) error
+}
+
+// Args are the parsed arguments of a mask keyed by name,
+// they are shared between calls and must not be modified
+type Args map[string]any
+
+// String returns the argument declared as ArgString
+func (a Args) String(name string) string {
+	v, _ := a[name].(string)
+	return v
+}
+
+// Int returns the argument declared as ArgInt
+func (a Args) Int(name string) int {
+	v, _ := a[name].(int)
+	return v
+}
+
+// Uint returns the argument declared as ArgUint
+func (a Args) Uint(name string) uint {
+	v, _ := a[name].(uint)
+	return v
+}
+
+// Float returns the argument declared as ArgFloat
+func (a Args) Float(name string) float64 {
+	v, _ := a[name].(float64)
+	return v
+}
+
+// Bool returns the argument declared as ArgBool
+func (a Args) Bool(name string) bool {
+	v, _ := a[name].(bool)
+	return v
+}
+
+// parseArgs parses the raw arguments of a tag by the declared schema
+func parseArgs(schema []Arg, raw []string) (Args, error) {
+	if len(raw) > len(schema) {
+		return nil, fmt.Errorf("%w: expect at most %d arguments, got %d", ErrInvalidArgument, len(schema), len(raw))
+	}
+
+	args := make(Args, len(schema))
+	for i, arg := range schema {
+		if i >= len(raw) || len(raw[i]) == 0 {
+			if arg.Default == nil {
+				return nil, fmt.Errorf("%w: argument %s is required", ErrInvalidArgument, arg.Name)
+			}
+			args[arg.Name] = arg.Default
+			continue
+		}
+
+		v, err := parseArg(arg.Type, raw[i])
+		if err != nil {
+			return nil, fmt.Errorf("%w: argument %s must be %s: %v", ErrInvalidArgument, arg.Name, arg.Type, err)
+		}
+		if arg.Check != nil {
+			if err = arg.Check(v); err != nil {
+				return nil, fmt.Errorf("%w: argument %s: %v", ErrInvalidArgument, arg.Name, err)
+			}
+		}
+		args[arg.Name] = v
+	}
+
+	return args, nil
+}
+
+func parseArg(t ArgType, raw string) (any, error) {
+	switch t {
+	case ArgString:
+		return raw, nil
+	case ArgInt:
+		return strconv.Atoi(raw)
+	case ArgUint:
+		v, err := strconv.ParseUint(raw, 10, strconv.IntSize)
+		return uint(v), err
+	case ArgFloat:
+		return strconv.ParseFloat(raw, 64)
+	case ArgBool:
+		return strconv.ParseBool(raw)
+	default:
+		return nil, fmt.Errorf("unknown %s", t)
+	}
+}
+
+// parsedArgs is the memoized result of parseArgs
+type parsedArgs struct {
+	args Args
+	err  error
+}
+
+// argSchema is the schema of a mask registered with RegMaskArgsFunc,
+// the raw arguments of each distinct tag are parsed once when the tag is
+// compiled and the result is kept for the calls of the mask
+type argSchema struct {
+	args   []Arg
+	parsed *sync.Map
+}
+
+// parse returns the typed arguments of raw, parsing them on first use
+func (a *argSchema) parse(raw []string) (Args, error) {
+	key := strings.Join(raw, "\x00")
+	parsed, cached := a.parsed.Load(key)
+	if !cached {
+		args, err := parseArgs(a.args, raw)
+		parsed, _ = a.parsed.LoadOrStore(key, parsedArgs{args: args, err: err})
+	}
+	return parsed.(parsedArgs).args, parsed.(parsedArgs).err
+}
+
+// RegMaskArgsFunc registers a mask for values of type T whose arguments are
+// declared by schema. The raw arguments of a tag are parsed and checked
+// when the tag is compiled, so Validate reports bad arguments, and the mask
+// only receives valid typed arguments. The arguments can also be given by
+// name in tags, such as `mask:"repeat(char='-')"`.
+// T must be one of the types a Masker has a registry for, such as string,
+// int64 or any, otherwise RegMaskArgsFunc panics.
+//
+// Example:
+//
+//	gmask.RegMaskArgsFunc(m, "repeat", func(value string, args gmask.Args) (string, error) {
+//		return strings.Repeat(args.String("char"), args.Int("count")), nil
+//	}, gmask.Arg{Name: "count", Type: gmask.ArgInt, Default: 8}, gmask.Arg{Name: "char", Default: "*"})
+func RegMaskArgsFunc[T any](m *Masker, maskName string, mask func(value T, args Args) (T, error), schema ...Arg) *Masker {
+	names := make([]string, len(schema))
+	for i, arg := range schema {
+		names[i] = arg.Name
+	}
+	as := &argSchema{args: schema, parsed: new(sync.Map)}
+	m.update(func(c *config) {
+		c.argNames[maskName] = names
+		c.argSchemas[maskName] = as
+		c.tags = new(sync.Map)
+	})
+
+	fn := func(value T, arg ...string) (T, error) {
+		args, err := as.parse(arg)
+		if err != nil {
+			var zero T
+			return zero, err
+		}
+		return mask(value, args)
+	}
+
+	switch f := any(fn).(type) {
+	case func(string, ...string) (string, error):
+		return m.RegMaskStringFunc(maskName, f)
+	case func(int, ...string) (int, error):
+		return m.RegMaskIntFunc(maskName, f)
+	case func(uint, ...string) (uint, error):
+		return m.RegMaskUintFunc(maskName, f)
+	case func(float64, ...string) (float64, error):
+		return m.RegMaskFloat64Func(maskName, f)
+	case func(any, ...string) (any, error):
+		return m.RegMaskAnyFunc(maskName, f)
+	case func(float32, ...string) (float32, error):
+		return m.RegMaskFloat32Func(maskName, f)
+	case func(int8, ...string) (int8, error):
+		return m.RegMaskInt8Func(maskName, f)
+	case func(int16, ...string) (int16, error):
+		return m.RegMaskInt16Func(maskName, f)
+	case func(int32, ...string) (int32, error):
+		return m.RegMaskInt32Func(maskName, f)
+	case func(int64, ...string) (int64, error):
+		return m.RegMaskInt64Func(maskName, f)
+	case func(uint8, ...string) (uint8, error):
+		return m.RegMaskUint8Func(maskName, f)
+	case func(uint16, ...string) (uint16, error):
+		return m.RegMaskUint16Func(maskName, f)
+	case func(uint32, ...string) (uint32, error):
+		return m.RegMaskUint32Func(maskName, f)
+	case func(uint64, ...string) (uint64, error):
+		return m.RegMaskUint64Func(maskName, f)
+	case func(bool, ...string) (bool, error):
+		return m.RegMaskBoolFunc(maskName, f)
+	case func(complex64, ...string) (complex64, error):
+		return m.RegMaskComplex64Func(maskName, f)
+	case func(complex128, ...string) (complex128, error):
+		return m.RegMaskComplex128Func(maskName, f)
+	default:
+		panic(fmt.Sprintf("gmask: no registry for masks of %T", *new(T)))
+	}
+}
@@ -0,0 +1,76 @@
+package gmask
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestRegMaskArgsFunc(t *testing.T) {
+	calls := 0
+	m := RegMaskArgsFunc(New(), "repeat", func(value string, args Args) (string, error) {
+		calls++
+		return strings.Repeat(args.String("char"), args.Int("count")), nil
+	},
+		Arg{Name: "count", Type: ArgInt, Default: 8, Check: func(value any) error {
+			if value.(int) < 0 {
+				return errors.New("count must not be negative")
+			}
+			return nil
+		}},
+		Arg{Name: "char", Default: "*"},
+	)
+
+	masked, err := m.String("secret", "repeat")
+	assert.NoError(t, err)
+	assert.Equal(t, "********", masked)
+
+	masked, err = m.String("secret", "repeat", "3", "-")
+	assert.NoError(t, err)
+	assert.Equal(t, "---", masked)
+
+	masked, err = m.String("secret", "repeat", "", "-")
+	assert.NoError(t, err)
+	assert.Equal(t, "--------", masked)
+
+	// bad arguments never reach the mask
+	calls = 0
+	_, err = m.String("secret", "repeat", "x")
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+	_, err = m.String("secret", "repeat", "-1")
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+	_, err = m.String("secret", "repeat", "1", "-", "extra")
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+	assert.Zero(t, calls)
+
+	// typed arguments of tags are checked when the tags are compiled
+	type account struct {
+		Name  string `mask:"repeat(count=2, char='-')"`
+		Token string `mask:"repeat,x"`
+	}
+	assert.NoError(t, m.Validate(struct {
+		Name string `mask:"repeat(count=2, char='-')"`
+	}{}))
+	err = m.Validate(account{})
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+	assert.ErrorContains(t, err, "Token")
+	_, err = m.Mask(account{Name: "foo", Token: "bar"})
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+
+	// required arguments
+	m = RegMaskArgsFunc(m, "fixed", func(value int64, args Args) (int64, error) {
+		return int64(args.Int("value")), nil
+	}, Arg{Name: "value", Type: ArgInt})
+	_, err = m.Int64(1, "fixed")
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+	i, err := m.Int64(1, "fixed", "7")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), i)
+
+	assert.Panics(t, func() {
+		RegMaskArgsFunc(New(), "struct", func(value struct{}, args Args) (struct{}, error) {
+			return value, nil
+		})
+	})
+}
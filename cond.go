@@ -0,0 +1,210 @@
+package gmask
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const condTagName = "maskif"
+
+// MaskPredicate decides whether a struct field is masked, see RegMaskPredicate
+type MaskPredicate func(fc FieldContext) bool
+
+// RegMaskPredicate registers a predicate which can be named in the maskif
+// tag of a field, the field is only masked when the predicate holds.
+// A predicate prefixed with ! in the tag is negated.
+//
+// Example:
+//
+//	m.RegMaskPredicate("eu", func(fc gmask.FieldContext) bool {
+//		return euCountries[fc.Parent.FieldByName("Country").String()]
+//	})
+//
+// then a field tagged `mask:"zero" maskif:"eu"` is zeroed for EU addresses only.
+func (m *Masker) RegMaskPredicate(name string, predicate MaskPredicate) *Masker {
+	return m.update(func(c *config) {
+		c.predicates[name] = predicate
+	})
+}
+
+// cond is a parsed maskif tag, it holds when all of its terms hold
+type cond []condTerm
+
+// condTerm is either a predicate or a comparison of a sibling field
+type condTerm struct {
+	// predicate is the name of a registered predicate
+	predicate string
+	// field is the name of the compared sibling field
+	field string
+	// values are the values the field is compared with, any of them matches
+	values []string
+	negate bool
+}
+
+// condTag keys parsed maskif tags in the tag cache of a masker
+type condTag string
+
+// parsedCond is the memoized result of compileCond
+type parsedCond struct {
+	cond cond
+	err  error
+}
+
+func (c *config) parseCond(tag string) (cond, error) {
+	if parsed, cached := c.tags.Load(condTag(tag)); cached {
+		return parsed.(parsedCond).cond, parsed.(parsedCond).err
+	}
+
+	parsed, err := compileCond(tag)
+	c.tags.Store(condTag(tag), parsedCond{cond: parsed, err: err})
+	return parsed, err
+}
+
+// compileCond parses a maskif tag made of terms joined by &&, each term is
+//
+//	Field==value   the sibling Field equals value, values separated by |
+//	               are alternatives, such as `Country==DE|FR|IT`
+//	Field!=value   the sibling Field equals none of the values
+//	name           the predicate registered under name holds
+//	!name          the predicate registered under name does not hold
+func compileCond(tag string) (cond, error) {
+	var c cond
+	for _, raw := range strings.Split(tag, "&&") {
+		raw = strings.TrimSpace(raw)
+		var term condTerm
+		if i := strings.Index(raw, "=="); i >= 0 {
+			term.field, term.values = raw[:i], strings.Split(raw[i+2:], "|")
+		} else if i = strings.Index(raw, "!="); i >= 0 {
+			term.field, term.values, term.negate = raw[:i], strings.Split(raw[i+2:], "|"), true
+		} else {
+			term.predicate = strings.TrimPrefix(raw, "!")
+			term.negate = len(term.predicate) != len(raw)
+		}
+
+		name := strings.TrimSpace(term.field + term.predicate)
+		if !isIdentString(name) {
+			return nil, fmt.Errorf("%w: maskif %q: bad term %q", ErrInvalidArgument, tag, raw)
+		}
+		if term.field != "" {
+			term.field = name
+			for i, v := range term.values {
+				term.values[i] = strings.TrimSpace(v)
+			}
+		} else {
+			term.predicate = name
+		}
+		c = append(c, term)
+	}
+
+	return c, nil
+}
+
+func isIdentString(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isIdent(s[i], i == 0) {
+			return false
+		}
+	}
+	return len(s) != 0
+}
+
+// holds evaluates c against the struct holding the field being masked
+func (m *Masker) holds(s *maskState, c cond) (bool, error) {
+	for _, term := range c {
+		var ok bool
+		if term.predicate != "" {
+			predicate, exist := s.cfg.predicates[term.predicate]
+			if !exist {
+				return false, fmt.Errorf("%w: predicate %s", ErrUnknownStrategy, term.predicate)
+			}
+			ok = predicate(FieldContext{
+				Context: s.ctx,
+				Path:    s.pathString(),
+				Field:   s.field,
+				Parent:  s.parent,
+				Masker:  m,
+			})
+		} else {
+			field, exist := s.parent.Type().FieldByName(term.field)
+			if !exist {
+				return false, fmt.Errorf("%w: maskif: %s has no field %s", ErrInvalidArgument, s.parent.Type(), term.field)
+			}
+			// a field promoted through a nil embedded pointer reads as zero
+			fv, err := s.parent.FieldByIndexErr(field.Index)
+			if err != nil {
+				fv = reflect.Zero(field.Type)
+			}
+			ok = matches(fv, term.values)
+		}
+
+		if ok == term.negate {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// readsParent reports whether masking the fields of the struct type rt may
+// read the struct holding them, through maskif tags or field masks
+func (c *config) readsParent(rt reflect.Type) bool {
+	if len(c.maskFieldFuncMap) != 0 {
+		return true
+	}
+	for i := 0; i < rt.NumField(); i++ {
+		if hasCond(rt.Field(i)) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasCond reports whether field has a maskif tag
+func hasCond(field reflect.StructField) bool {
+	_, exist := field.Tag.Lookup(condTagName)
+	return exist
+}
+
+// matches reports whether the printed value of rv is one of values,
+// a nil pointer or interface is printed as the empty string
+func matches(rv reflect.Value, values []string) bool {
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			rv = reflect.Value{}
+			break
+		}
+		rv = rv.Elem()
+	}
+
+	printed := ""
+	if rv.IsValid() {
+		printed = fmt.Sprint(rv)
+	}
+	for _, v := range values {
+		if v == printed {
+			return true
+		}
+	}
+	return false
+}
+
+// validateCond checks the maskif tag of a field of the struct type rt
+func (c *config) validateCond(rt reflect.Type, tag string) error {
+	parsed, err := c.parseCond(tag)
+	if err != nil {
+		return err
+	}
+
+	for _, term := range parsed {
+		if term.predicate != "" {
+			if _, exist := c.predicates[term.predicate]; !exist {
+				return fmt.Errorf("%w: predicate %s", ErrUnknownStrategy, term.predicate)
+			}
+		} else if _, exist := rt.FieldByName(term.field); !exist {
+			return fmt.Errorf("%w: maskif: %s has no field %s", ErrInvalidArgument, rt, term.field)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,153 @@
+package gmask
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMasker_MaskIf(t *testing.T) {
+	type setting struct {
+		Type  string
+		Key   string
+		Value string `mask:"char,3" maskif:"Type==password|token"`
+	}
+	type address struct {
+		Country string
+		Private *bool
+		Street  string `mask:"zero" maskif:"eu && Private!=false"`
+	}
+
+	private, public := true, false
+	m := New().
+		RegMaskAnyFunc(MaskTypeZero, MaskZero).
+		RegMaskStringFunc(MaskTypeChar, MaskCharString()).
+		RegMaskPredicate("eu", func(fc FieldContext) bool {
+			switch fc.Parent.FieldByName("Country").String() {
+			case "DE", "FR":
+				return true
+			}
+			return false
+		})
+
+	settings := []setting{
+		{Type: "password", Key: "db", Value: "secret"},
+		{Type: "host", Key: "db", Value: "localhost"},
+		{Type: "token", Key: "api", Value: "abc"},
+	}
+	masked, err := m.Mask(settings)
+	assert.NoError(t, err)
+	assert.Equal(t, []setting{
+		{Type: "password", Key: "db", Value: "***"},
+		{Type: "host", Key: "db", Value: "localhost"},
+		{Type: "token", Key: "api", Value: "***"},
+	}, masked)
+
+	addresses := []address{
+		{Country: "DE", Street: "Hauptstr. 1"},
+		{Country: "DE", Private: &private, Street: "Hauptstr. 2"},
+		{Country: "DE", Private: &public, Street: "Hauptstr. 3"},
+		{Country: "US", Street: "Main St. 1"},
+	}
+	masked, err = m.Mask(addresses)
+	assert.NoError(t, err)
+	assert.Equal(t, []address{
+		{Country: "DE"},
+		{Country: "DE", Private: &private},
+		{Country: "DE", Private: &public, Street: "Hauptstr. 3"},
+		{Country: "US", Street: "Main St. 1"},
+	}, masked)
+
+	err = m.MaskInPlace(&settings)
+	assert.NoError(t, err)
+	assert.Equal(t, "***", settings[0].Value)
+	assert.Equal(t, "localhost", settings[1].Value)
+
+	assert.NoError(t, m.Validate(setting{}))
+	assert.NoError(t, m.Validate(address{}))
+
+	type broken struct {
+		Missing string `mask:"zero" maskif:"Kind==secret"`
+		Unknown string `mask:"zero" maskif:"!nope"`
+		Bad     string `mask:"zero" maskif:"a b"`
+	}
+	err = m.Validate(broken{})
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+	assert.ErrorIs(t, err, ErrUnknownStrategy)
+
+	_, err = m.Mask(broken{Missing: "x"})
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+}
+
+func TestMasker_MaskIfInPlaceOrder(t *testing.T) {
+	type secret struct {
+		Type  string `mask:"zero"`
+		Value string `mask:"char" maskif:"Type==password"`
+	}
+	m := NewWithDefaults()
+
+	// Type is zeroed before Value is masked, the condition still reads it
+	demo := secret{Type: "password", Value: "hunter2"}
+	masked, err := m.Mask(demo)
+	assert.NoError(t, err)
+	assert.Equal(t, secret{Value: "********"}, masked)
+	assert.NoError(t, m.MaskInPlace(&demo))
+	assert.Equal(t, secret{Value: "********"}, demo)
+}
+
+func TestMasker_MaskIfNilEmbedded(t *testing.T) {
+	type Kind struct {
+		Type string
+	}
+	type secret struct {
+		*Kind
+		Value string `mask:"char" maskif:"Type!=public"`
+	}
+	m := NewWithDefaults()
+
+	// a field promoted through a nil pointer reads as zero
+	masked, err := m.Mask(secret{Value: "hunter2"})
+	assert.NoError(t, err)
+	assert.Equal(t, secret{Value: "********"}, masked)
+
+	masked, err = m.Mask(secret{Kind: &Kind{Type: "public"}, Value: "hunter2"})
+	assert.NoError(t, err)
+	assert.Equal(t, "hunter2", masked.(secret).Value)
+	assert.NoError(t, m.Validate(secret{}))
+}
+
+func TestMasker_MaskIfUntagged(t *testing.T) {
+	type setting struct {
+		Type  string `mask:"public"`
+		Value string `mask:"char,3" maskif:"Type==password"`
+	}
+	type account struct {
+		Kind     string
+		Password string `maskif:"Kind==user"`
+	}
+
+	// a condition which does not hold leaves the field to the kind defaults
+	m := NewWithDefaults().SetDenyByDefault(true).SetKindDefault(reflect.String, "char,1")
+	masked, err := m.Mask(setting{Type: "api_token", Value: "secret"})
+	assert.NoError(t, err)
+	assert.Equal(t, setting{Type: "api_token", Value: "*"}, masked)
+	masked, err = m.Mask(setting{Type: "password", Value: "secret"})
+	assert.NoError(t, err)
+	assert.Equal(t, setting{Type: "password", Value: "***"}, masked)
+
+	demo := setting{Type: "api_token", Value: "secret"}
+	assert.NoError(t, m.MaskInPlace(&demo))
+	assert.Equal(t, setting{Type: "api_token", Value: "*"}, demo)
+
+	// and to the name heuristics
+	m = NewWithDefaults().SetNameHeuristics(MaskTypeRedact, SensitiveNames...)
+	masked, err = m.Mask(account{Kind: "service", Password: "secret"})
+	assert.NoError(t, err)
+	assert.Equal(t, account{Kind: "service", Password: Redacted}, masked)
+	assert.NoError(t, m.Validate(account{}))
+
+	acc := account{Kind: "service", Password: "secret"}
+	assert.NoError(t, m.MaskInPlace(&acc))
+	assert.Equal(t, account{Kind: "service", Password: Redacted}, acc)
+}
@@ -1,43 +1,153 @@
 package gmask
 
-var defaultMasker *Masker
+import (
+	"context"
+	"sync/atomic"
+)
+
+// defaultMasker is used by the package level functions
+var defaultMasker atomic.Pointer[Masker]
 
 func init() {
-	defaultMasker = New().
+	defaultMasker.Store(NewWithDefaults())
+}
+
+// NewWithDefaults returns a masker with the built-in masks registered,
+// the ones the package level functions start with
+func NewWithDefaults() *Masker {
+	return New(WithDefaults())
+}
+
+func registerDefaults(m *Masker) *Masker {
+	m.
 		RegMaskAnyFunc(MaskTypeZero, MaskZero).
+		RegMaskAnyFunc(MaskTypeOmit, MaskZero).
+		RegMaskAnyFunc(MaskTypeLen, MaskLen).
+		RegMaskAnyFunc(MaskTypeRedact, MaskRedacted).
 		RegMaskStringFunc(MaskTypeChar, MaskCharString()).
 		RegMaskStringFunc(MaskTypeRandom, MaskRandString).
 		RegMaskStringFunc(MaskTypeHash, MaskHashString).
+		RegMaskStringFunc(MaskTypeJSON, MaskJSONString).
 		RegMaskIntFunc(MaskTypeRandom, MaskRandInt).
 		RegMaskFloat64Func(MaskTypeRandom, MaskRandFloat64).
-		RegMaskUintFunc(MaskTypeRandom, MaskRandUint)
+		RegMaskUintFunc(MaskTypeRandom, MaskRandUint).
+		RegMaskFloat32Func(MaskTypeRandom, MaskRandFloat32).
+		RegMaskInt8Func(MaskTypeRandom, MaskRandInt8).
+		RegMaskInt16Func(MaskTypeRandom, MaskRandInt16).
+		RegMaskInt32Func(MaskTypeRandom, MaskRandInt32).
+		RegMaskInt64Func(MaskTypeRandom, MaskRandInt64).
+		RegMaskUint8Func(MaskTypeRandom, MaskRandUint8).
+		RegMaskUint16Func(MaskTypeRandom, MaskRandUint16).
+		RegMaskUint32Func(MaskTypeRandom, MaskRandUint32).
+		RegMaskUint64Func(MaskTypeRandom, MaskRandUint64).
+		RegMaskBoolFunc(MaskTypeRandom, MaskRandBool).
+		RegMaskComplex64Func(MaskTypeRandom, MaskRandComplex64).
+		RegMaskComplex128Func(MaskTypeRandom, MaskRandComplex128)
+	return RegMaskArgsFunc(m, MaskTypePartial, MaskPartialString, PartialArgs...)
+}
+
+// Default returns the masker used by the package level functions,
+// masks registered on it are available to them
+//
+// Example: gmask.Default().RegMaskStringFunc("email", maskEmail)
+func Default() *Masker {
+	return defaultMasker.Load()
+}
+
+// SetDefault replaces the masker used by the package level functions,
+// calls already running keep the previous one
+func SetDefault(m *Masker) {
+	if m == nil {
+		panic("gmask: nil default masker")
+	}
+	defaultMasker.Store(m)
 }
 
+// Mask masks target with the default masker, see Masker.Mask. With a
+// fallback set, the masked value is returned along with the errors.
 func Mask[T any](target T) (ret T, err error) {
-	v, err := defaultMasker.Mask(target)
-	if err != nil {
-		return ret, err
+	v, err := Default().Mask(target)
+	if v != nil {
+		ret = v.(T)
 	}
 
-	return v.(T), nil
+	return ret, err
+}
+
+// MaskContext is Mask with a context, see Masker.MaskContext
+func MaskContext[T any](ctx context.Context, target T) (ret T, err error) {
+	v, err := Default().MaskContext(ctx, target)
+	if v != nil {
+		ret = v.(T)
+	}
+
+	return ret, err
 }
 
 func Float64(value float64, tag ...string) (float64, error) {
-	return defaultMasker.Float64(value, tag...)
+	return Default().Float64(value, tag...)
 }
 
 func String(value string, tag ...string) (string, error) {
-	return defaultMasker.String(value, tag...)
+	return Default().String(value, tag...)
 }
 
 func Int(value int, tag ...string) (int, error) {
-	return defaultMasker.Int(value, tag...)
+	return Default().Int(value, tag...)
 }
 
 func Uint(value uint, tag ...string) (uint, error) {
-	return defaultMasker.Uint(value, tag...)
+	return Default().Uint(value, tag...)
 }
 
 func Any(value any, tag ...string) (hit bool, output any, err error) {
-	return defaultMasker.Any(value, tag...)
+	return Default().Any(value, tag...)
+}
+
+func Float32(value float32, tag ...string) (float32, error) {
+	return Default().Float32(value, tag...)
+}
+
+func Int8(value int8, tag ...string) (int8, error) {
+	return Default().Int8(value, tag...)
+}
+
+func Int16(value int16, tag ...string) (int16, error) {
+	return Default().Int16(value, tag...)
+}
+
+func Int32(value int32, tag ...string) (int32, error) {
+	return Default().Int32(value, tag...)
+}
+
+func Int64(value int64, tag ...string) (int64, error) {
+	return Default().Int64(value, tag...)
+}
+
+func Uint8(value uint8, tag ...string) (uint8, error) {
+	return Default().Uint8(value, tag...)
+}
+
+func Uint16(value uint16, tag ...string) (uint16, error) {
+	return Default().Uint16(value, tag...)
+}
+
+func Uint32(value uint32, tag ...string) (uint32, error) {
+	return Default().Uint32(value, tag...)
+}
+
+func Uint64(value uint64, tag ...string) (uint64, error) {
+	return Default().Uint64(value, tag...)
+}
+
+func Bool(value bool, tag ...string) (bool, error) {
+	return Default().Bool(value, tag...)
+}
+
+func Complex64(value complex64, tag ...string) (complex64, error) {
+	return Default().Complex64(value, tag...)
+}
+
+func Complex128(value complex128, tag ...string) (complex128, error) {
+	return Default().Complex128(value, tag...)
 }
@@ -0,0 +1,102 @@
+package gmask
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// MaskTypePublic marks a value as safe to show, in deny by default mode it
+// is the only way to leave a scalar unmasked. On a slice, array, map or
+// pointer it applies to the elements, on a struct each field still follows
+// its own tag.
+//
+// Example: `mask:"public"`
+const MaskTypePublic = "public"
+
+// SetDenyByDefault switches deny by default mode, where every untagged
+// string, []byte, bool or number, including the elements and map keys of
+// untagged containers, is masked with the default of its kind unless tagged
+// `mask:"public"` or `mask:"-"`. So a field added to a struct is never
+// shown until it is declared public. Map keys masked alike fail with
+// ErrKeyCollision rather than merging their entries.
+func (m *Masker) SetDenyByDefault(deny bool) *Masker {
+	return m.update(func(c *config) {
+		c.denyByDefault = deny
+	})
+}
+
+// SetKindDefault sets the tag masking untagged values of kind in deny by
+// default mode, byte slices use the default of reflect.String and "-"
+// leaves the kind unmasked. Kinds without default are replaced with the
+// zero value. SetKindDefault panics if the tag can not be parsed.
+//
+// Example: SetKindDefault(reflect.String, "char,3")
+func (m *Masker) SetKindDefault(kind reflect.Kind, tag string) *Masker {
+	parsed, err := m.c().parseTag(tag)
+	if err != nil {
+		panic(fmt.Sprintf("gmask: default of %s: %v", kind, err))
+	}
+	if tag == keepTag {
+		parsed = []string{MaskTypePublic}
+	}
+
+	return m.update(func(c *config) {
+		c.kindDefaults[kind] = parsed
+	})
+}
+
+// denyTag returns the tag masking a value of type rt given its tag, public
+// tags are dropped once they reach a value without elements and untagged
+// scalars get the default of their kind in deny by default mode. zero is
+// true when such a scalar has no default and must be zeroed.
+func (c *config) denyTag(rt reflect.Type, tag []string) (tag2 []string, zero bool) {
+	kind := rt.Kind()
+	if isBytes(rt) {
+		kind = reflect.String
+	}
+
+	if c.denyByDefault && (len(tag) == 0 || len(tag[0]) == 0) && isScalar(kind) {
+		tag2, exist := c.kindDefaults[kind]
+		if !exist {
+			return nil, true
+		}
+		tag = tag2
+	}
+
+	if len(tag) != 0 && tag[0] == MaskTypePublic {
+		switch kind {
+		case reflect.Slice, reflect.Array, reflect.Map, reflect.Ptr, reflect.Interface:
+			return tag, false
+		default:
+			return nil, false
+		}
+	}
+	return tag, false
+}
+
+// masksKeys reports whether the keys of a map of type rt whose entries are
+// tagged tag are masked, which happens in deny by default mode unless the
+// map is public. Keys masked alike fail with ErrKeyCollision instead of
+// merging their entries, the fallback then replaces the whole map.
+func (c *config) masksKeys(rt reflect.Type, tag []string) bool {
+	if !c.denyByDefault || len(tag) != 0 && tag[0] == MaskTypePublic {
+		return false
+	}
+	return isScalar(rt.Key().Kind())
+}
+
+func isBytes(rt reflect.Type) bool {
+	return rt.Kind() == reflect.Slice && rt.Elem().Kind() == reflect.Uint8
+}
+
+func isScalar(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,143 @@
+package gmask
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMasker_SetDenyByDefault(t *testing.T) {
+	type profile struct {
+		Bio   string
+		Likes []string
+	}
+	type user struct {
+		ID      int    `mask:"public"`
+		Name    string `mask:"-"`
+		Email   string `mask:"char,3"`
+		Phone   string
+		Age     int
+		Token   []byte
+		Roles   []string `mask:"public"`
+		Tags    map[string]string
+		Any     any
+		Any2    any      `mask:"public"`
+		Profile *profile `mask:"public"`
+	}
+	demo := user{
+		ID:      1,
+		Name:    "foo",
+		Email:   "foo@bar.com",
+		Phone:   "123",
+		Age:     18,
+		Token:   []byte("token"),
+		Roles:   []string{"admin"},
+		Tags:    map[string]string{"k": "v"},
+		Any:     "any",
+		Any2:    "any",
+		Profile: &profile{Bio: "bio", Likes: []string{"go"}},
+	}
+
+	m := New().RegMaskStringFunc(MaskTypeChar, MaskCharString())
+	masked, err := m.Mask(demo)
+	assert.NoError(t, err)
+	assert.Equal(t, "123", masked.(user).Phone)
+
+	m.SetDenyByDefault(true)
+	expected := user{
+		ID:      1,
+		Name:    "foo",
+		Email:   "***",
+		Token:   []byte{},
+		Roles:   []string{"admin"},
+		Tags:    map[string]string{"": ""},
+		Any:     "",
+		Any2:    "any",
+		Profile: &profile{Likes: []string{""}},
+	}
+	expected.Token = nil
+	masked, err = m.Mask(demo)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, masked)
+
+	m.SetKindDefault(reflect.String, "char,1").SetKindDefault(reflect.Int, "char")
+	expected.Phone, expected.Token, expected.Tags, expected.Any = "*", []byte("*"), map[string]string{"*": "*"}, "*"
+	expected.Profile = &profile{Bio: "*", Likes: []string{"*"}}
+	masked, err = m.Mask(demo)
+	assert.ErrorIs(t, err, ErrUnsupportedKind)
+	assert.ErrorIs(t, m.Validate(demo), ErrUnsupportedKind)
+
+	m.SetKindDefault(reflect.Int, "-")
+	masked, err = m.Mask(demo)
+	assert.NoError(t, err)
+	expected.Age = 18
+	assert.Equal(t, expected, masked)
+	assert.NoError(t, m.Validate(demo))
+
+	err = m.MaskInPlace(&demo)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, demo)
+
+	assert.Panics(t, func() { m.SetKindDefault(reflect.String, "char(") })
+}
+
+func TestMasker_SetDenyByDefaultKeys(t *testing.T) {
+	type user struct {
+		Emails map[string]string
+		Scores map[int]bool
+		Public map[string]string `mask:"public"`
+	}
+	demo := user{
+		Emails: map[string]string{"alice@x.com": "", "bob@example.com": "b"},
+		Scores: map[int]bool{7: true},
+		Public: map[string]string{"alice@x.com": "a"},
+	}
+
+	// keys are masked like values
+	m := New().RegMaskStringFunc(MaskTypeChar, MaskCharString()).
+		SetDenyByDefault(true).
+		SetKindDefault(reflect.String, "char,-1")
+	expected := user{
+		Emails: map[string]string{"***********": "", "***************": "*"},
+		Scores: map[int]bool{0: false},
+		Public: map[string]string{"alice@x.com": "a"},
+	}
+	masked, err := m.Mask(demo)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, masked)
+	assert.NoError(t, m.Validate(user{}))
+
+	err = m.MaskInPlace(&demo)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, demo)
+
+	m.SetKindDefault(reflect.Int, "char")
+	assert.ErrorIs(t, m.Validate(user{}), ErrUnsupportedKind)
+
+	// keys masked alike are refused rather than merged
+	type book struct {
+		Emails map[string]int
+	}
+	m = New(WithDefaults()).SetDenyByDefault(true).SetKindDefault(reflect.String, "char,3")
+	_, err = m.Mask(book{Emails: map[string]int{"alice@example.com": 1, "bob@example.com": 2}})
+	assert.ErrorIs(t, err, ErrKeyCollision)
+	var maskErr *MaskError
+	assert.ErrorAs(t, err, &maskErr)
+	assert.Equal(t, "book.Emails", maskErr.Path)
+	b := book{Emails: map[string]int{"alice@example.com": 1, "bob@example.com": 2}}
+	assert.ErrorIs(t, m.MaskInPlace(&b), ErrKeyCollision)
+
+	// the fallback replaces the whole map
+	m.SetFallback(MaskZero)
+	masked, err = m.Mask(book{Emails: map[string]int{"alice@example.com": 1, "bob@example.com": 2}})
+	assert.ErrorIs(t, err, ErrKeyCollision)
+	assert.Empty(t, masked.(book).Emails)
+	assert.ErrorIs(t, m.MaskInPlace(&b), ErrKeyCollision)
+	assert.Empty(t, b.Emails)
+
+	// a single key can not collide
+	masked, err = m.Mask(book{Emails: map[string]int{"alice@example.com": 1}})
+	assert.NoError(t, err)
+	assert.Equal(t, book{Emails: map[string]int{"***": 0}}, masked)
+}
@@ -0,0 +1,41 @@
+package gmask
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrUnknownStrategy reports a tag naming a mask that is not registered
+	ErrUnknownStrategy = errors.New("gmask: unknown strategy")
+	// ErrUnsupportedKind reports a tag naming a mask that is not registered
+	// for the kind of the masked value
+	ErrUnsupportedKind = errors.New("gmask: unsupported kind")
+	// ErrInvalidArgument reports an argument a mask can not accept
+	ErrInvalidArgument = errors.New("gmask: invalid argument")
+	// ErrMaxDepth reports a value nested deeper than the masker allows
+	ErrMaxDepth = errors.New("gmask: max depth exceeded")
+	// ErrKeyCollision reports map keys masked alike, which would merge
+	// their entries
+	ErrKeyCollision = errors.New("gmask: masked map keys collide")
+)
+
+// MaskError records a failure to mask a single value and where it happened
+type MaskError struct {
+	// Path of the value, such as User.Cards[2].Number, map keys are
+	// written [*] as they may be sensitive
+	Path string
+	// Tag is the whole tag applied to the value
+	Tag string
+	// Strategy is the name of the mask
+	Strategy string
+	Err      error
+}
+
+func (e *MaskError) Error() string {
+	return fmt.Sprintf("gmask: mask %s with %q: %v", e.Path, e.Tag, e.Err)
+}
+
+func (e *MaskError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,50 @@
+package gmask
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMaskError(t *testing.T) {
+	type card struct {
+		Number string `mask:"char,x"`
+	}
+	type user struct {
+		Name  string
+		Cards []card
+		Meta  map[string][]string `mask:"hash,sha512"`
+	}
+
+	_, err := Mask(user{Cards: []card{{}, {}, {Number: "4111"}}})
+	var maskErr *MaskError
+	assert.True(t, errors.As(err, &maskErr))
+	assert.Equal(t, "user.Cards[2].Number", maskErr.Path)
+	assert.Equal(t, "char,x", maskErr.Tag)
+	assert.Equal(t, "char", maskErr.Strategy)
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+
+	_, err = Mask(&user{Meta: map[string][]string{"foo": {"bar"}}})
+	assert.True(t, errors.As(err, &maskErr))
+	assert.Equal(t, "user.Meta[*][0]", maskErr.Path)
+	assert.NotContains(t, err.Error(), "foo")
+	err = Default().MaskInPlace(&user{Meta: map[string][]string{"alice@example.com": {"bar"}}})
+	assert.True(t, errors.As(err, &maskErr))
+	assert.Equal(t, "user.Meta[*][0]", maskErr.Path)
+	assert.Equal(t, "hash", maskErr.Strategy)
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+
+	err = Default().MaskInPlace(&user{Cards: []card{{}, {Number: "4111"}}})
+	assert.True(t, errors.As(err, &maskErr))
+	assert.Equal(t, "user.Cards[0].Number", maskErr.Path)
+
+	type node struct {
+		Next *node
+	}
+	_, err = New().SetMaxDepth(2).Mask(node{Next: &node{Next: &node{}}})
+	assert.ErrorIs(t, err, ErrMaxDepth)
+	assert.True(t, errors.As(err, &maskErr))
+	assert.Equal(t, "node.Next", maskErr.Path)
+
+	assert.ErrorIs(t, New().MaskInPlace(user{}), ErrInvalidArgument)
+}
@@ -0,0 +1,80 @@
+package gmask
+
+import (
+	"context"
+	"reflect"
+)
+
+// FieldContext describes where a value masked by a MaskFieldFunc was found
+type FieldContext struct {
+	// Context is the context of the Mask call
+	Context context.Context
+	// Path of the value, such as User.Cards[2].Number, map keys are
+	// written [*] as they may be sensitive
+	Path string
+	// Field is the struct field the value was reached through, elements of
+	// a slice, array or map share the field of their container.
+	// It is the zero StructField for values outside of any struct.
+	Field reflect.StructField
+	// Parent is the original struct holding Field, it must not be modified.
+	// It is the zero Value for values outside of any struct.
+	Parent reflect.Value
+	// Masker is the masker running the mask
+	Masker *Masker
+}
+
+// MaskFieldFunc masks a value of any kind knowing where it was found,
+// the result must be of the type of value or convertible to it,
+// nil is the zero value of the type
+type MaskFieldFunc func(fc FieldContext, value any, arg ...string) (any, error)
+
+// RegMaskFieldFunc registers a mask receiving the FieldContext of the
+// masked value, such as a mask salting a hash with the field name.
+// Like the any masks it replaces struct, slice, array and map values as
+// a whole unless the tag is prefixed with each:, and it is tried before
+// the masks registered for a kind.
+//
+// Example:
+//
+//	m.RegMaskFieldFunc("salted", func(fc gmask.FieldContext, value any, arg ...string) (any, error) {
+//		return gmask.MaskHashString(fc.Field.Name+fmt.Sprint(value), arg...)
+//	})
+func (m *Masker) RegMaskFieldFunc(maskName string, mask MaskFieldFunc) *Masker {
+	return m.update(func(c *config) {
+		c.maskFieldFuncMap[maskName] = mask
+	})
+}
+
+// maskField masks rv with the field mask named by the tag
+func (m *Masker) maskField(s *maskState, rv reflect.Value, mp reflect.Value, tag ...string) (hit bool, output reflect.Value, err error) {
+	if len(tag) == 0 || len(tag[0]) == 0 {
+		return false, rv, nil
+	}
+	maskFunc, exist := s.cfg.maskFieldFuncMap[tag[0]]
+	if !exist {
+		return false, rv, nil
+	}
+
+	fc := FieldContext{
+		Context: s.ctx,
+		Path:    s.pathString(),
+		Field:   s.field,
+		Parent:  s.parent,
+		Masker:  m,
+	}
+	v, err := maskFunc(fc, rv.Interface(), tag[1:]...)
+	if err != nil {
+		return true, reflect.Value{}, err
+	}
+
+	rv2, err := anyResult(v, rv.Type(), tag[0])
+	if err != nil {
+		return true, reflect.Value{}, err
+	}
+	if mp.IsValid() {
+		mp.Set(rv2)
+		return true, mp, nil
+	}
+
+	return true, rv2, nil
+}
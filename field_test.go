@@ -0,0 +1,99 @@
+package gmask
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMasker_RegMaskFieldFunc(t *testing.T) {
+	type card struct {
+		Number string `mask:"tweak" json:"number"`
+	}
+	type user struct {
+		ID    int
+		Email string `mask:"tweak" json:"email"`
+		Cards []card
+		Tags  []string `mask:"each:tweak"`
+	}
+
+	var paths []string
+	m := New().RegMaskFieldFunc("tweak", func(fc FieldContext, value any, arg ...string) (any, error) {
+		paths = append(paths, fc.Path)
+		assert.NotNil(t, fc.Context)
+		assert.NotNil(t, fc.Masker)
+		if id := fc.Parent.FieldByName("ID"); id.IsValid() {
+			return fmt.Sprintf("%s:%d", fc.Field.Name, id.Int()), nil
+		}
+		return fc.Field.Tag.Get("json"), nil
+	})
+
+	u := user{ID: 7, Email: "foo@bar.com", Cards: []card{{Number: "4111"}}, Tags: []string{"a", "b"}}
+	expected := user{ID: 7, Email: "Email:7", Cards: []card{{Number: "number"}}, Tags: []string{"Tags:7", "Tags:7"}}
+	masked, err := m.Mask(u)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, masked)
+	assert.Equal(t, []string{"user.Email", "user.Cards[0].Number", "user.Tags[0]", "user.Tags[1]"}, paths)
+
+	err = m.MaskInPlace(&u)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, u)
+
+	// the result must fit the type of the field
+	_, err = m.Mask(struct {
+		Flag bool `mask:"tweak"`
+	}{Flag: true})
+	assert.ErrorIs(t, err, ErrUnsupportedKind)
+
+	// an interface field takes any result implementing it
+	type event struct {
+		X any   `mask:"f"`
+		E error `mask:"f"`
+	}
+	m = New().RegMaskFieldFunc("f", func(fc FieldContext, value any, arg ...string) (any, error) {
+		if fc.Field.Name == "E" {
+			return errors.New("masked"), nil
+		}
+		return "masked", nil
+	})
+	ev := event{X: 42, E: errors.New("secret")}
+	masked, err = m.Mask(ev)
+	assert.NoError(t, err)
+	assert.Equal(t, "masked", masked.(event).X)
+	assert.EqualError(t, masked.(event).E, "masked")
+
+	assert.NoError(t, m.MaskInPlace(&ev))
+	assert.Equal(t, "masked", ev.X)
+	assert.EqualError(t, ev.E, "masked")
+}
+
+func TestMasker_MaskContext(t *testing.T) {
+	type tenantKey struct{}
+	type user struct {
+		Email string `mask:"tenant"`
+		Cards []string
+	}
+
+	m := New().RegMaskFieldFunc("tenant", func(fc FieldContext, value any, arg ...string) (any, error) {
+		tenant, _ := fc.Context.Value(tenantKey{}).(string)
+		return tenant + ":" + value.(string), nil
+	}).SetFallback(MaskZero)
+
+	u := user{Email: "foo@bar.com", Cards: []string{"4111", "4222"}}
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), tenantKey{}, "acme"))
+	masked, err := m.MaskContext(ctx, u)
+	assert.NoError(t, err)
+	assert.Equal(t, user{Email: "acme:foo@bar.com", Cards: []string{"4111", "4222"}}, masked)
+
+	cancel()
+	masked, err = m.MaskContext(ctx, u)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, masked)
+
+	err = m.MaskInPlace(&u)
+	assert.NoError(t, err)
+	assert.Equal(t, ":foo@bar.com", u.Email)
+}
@@ -1,11 +1,25 @@
 package gmask
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
 	"reflect"
 	"strings"
+	"sync"
+	"sync/atomic"
 )
 
-const tagName = "mask"
+// defaultTagName is the key of the struct tags holding mask rules,
+// see WithTagName
+const defaultTagName = "mask"
+
+// eachPrefix marks a tag on a struct, slice, array or map that should be
+// applied to every element instead of the value as a whole
+//
+// Example: `mask:"each:zero"`
+const eachPrefix = "each:"
 
 type (
 	MaskFloat64Func func(value float64, arg ...string) (float64, error)
@@ -13,167 +27,1115 @@ type (
 	MaskIntFunc     func(value int, arg ...string) (int, error)
 	MaskUintFunc    func(value uint, arg ...string) (uint, error)
 	MaskAnyFunc     func(value any, arg ...string) (any, error)
+
+	MaskFloat32Func    func(value float32, arg ...string) (float32, error)
+	MaskInt8Func       func(value int8, arg ...string) (int8, error)
+	MaskInt16Func      func(value int16, arg ...string) (int16, error)
+	MaskInt32Func      func(value int32, arg ...string) (int32, error)
+	MaskInt64Func      func(value int64, arg ...string) (int64, error)
+	MaskUint8Func      func(value uint8, arg ...string) (uint8, error)
+	MaskUint16Func     func(value uint16, arg ...string) (uint16, error)
+	MaskUint32Func     func(value uint32, arg ...string) (uint32, error)
+	MaskUint64Func     func(value uint64, arg ...string) (uint64, error)
+	MaskBoolFunc       func(value bool, arg ...string) (bool, error)
+	MaskComplex64Func  func(value complex64, arg ...string) (complex64, error)
+	MaskComplex128Func func(value complex128, arg ...string) (complex128, error)
 )
 
-type Masker struct {
-	// [MaskName] MaskFunction
-	maskFloat64FuncMap map[string]MaskFloat64Func
-	maskStringFuncMap  map[string]MaskStringFunc
-	maskIntFuncMap     map[string]MaskIntFunc
-	maskUintFuncMap    map[string]MaskUintFunc
-	maskAnyFuncMap     map[string]MaskAnyFunc
+// Masker masks values by the rules of their tags. It is safe for
+// concurrent use, registering masks and changing settings while masking
+// included: every change publishes a new configuration, Mask calls started
+// before keep reading the previous one.
+type Masker struct {
+	// mu serializes changes of the configuration
+	mu  sync.Mutex
+	cfg atomic.Pointer[config]
+	// policy maps type names to field paths to rules, see SetPolicy,
+	// it is swapped as a whole so a Mask call keeps the one it started with
+	policy atomic.Pointer[typeRules]
+}
+
+// config holds the registries and settings of a Masker,
+// it is never modified once published
+type config struct {
+	// [MaskName] MaskFunction
+	maskFloat64FuncMap map[string]MaskFloat64Func
+	maskStringFuncMap  map[string]MaskStringFunc
+	maskIntFuncMap     map[string]MaskIntFunc
+	maskUintFuncMap    map[string]MaskUintFunc
+	maskAnyFuncMap     map[string]MaskAnyFunc
+
+	// exact kind registries, tried before the wider registries above
+	maskFloat32FuncMap    map[string]MaskFloat32Func
+	maskInt8FuncMap       map[string]MaskInt8Func
+	maskInt16FuncMap      map[string]MaskInt16Func
+	maskInt32FuncMap      map[string]MaskInt32Func
+	maskInt64FuncMap      map[string]MaskInt64Func
+	maskUint8FuncMap      map[string]MaskUint8Func
+	maskUint16FuncMap     map[string]MaskUint16Func
+	maskUint32FuncMap     map[string]MaskUint32Func
+	maskUint64FuncMap     map[string]MaskUint64Func
+	maskBoolFuncMap       map[string]MaskBoolFunc
+	maskComplex64FuncMap  map[string]MaskComplex64Func
+	maskComplex128FuncMap map[string]MaskComplex128Func
+
+	// field masks, tried before the registries of any kind
+	maskFieldFuncMap map[string]MaskFieldFunc
+	// predicates of maskif tags, see RegMaskPredicate
+	predicates map[string]MaskPredicate
+
+	// keepUnexported copies unexported struct fields instead of zeroing them
+	keepUnexported bool
+	// maxDepth limits how deep a value is walked, 0 means unlimited
+	maxDepth int
+	// fallback replaces values failed to mask instead of aborting
+	fallback MaskAnyFunc
+	// strict refuses tags naming no mask for the kind of the value
+	strict bool
+	// tagName is the key of the struct tags holding mask rules
+	tagName string
+	// tagSources give rules to fields without mask tag, see RegTagSource
+	tagSources []tagSource
+	// nameTag masks untagged values whose names match namePatterns,
+	// see SetNameHeuristics
+	nameTag      string
+	namePatterns []string
+	// denyByDefault masks untagged scalars with kindDefaults,
+	// see SetDenyByDefault
+	denyByDefault bool
+	kindDefaults  map[reflect.Kind][]string
+
+	// argNames names the arguments of masks, see RegMaskArgNames
+	argNames map[string][]string
+	// argSchemas declares the arguments of masks, see RegMaskArgsFunc
+	argSchemas map[string]*argSchema
+	// tags caches parsed struct tags, the cache is shared by clones
+	// until the argument names change
+	tags *sync.Map
+}
+
+// New returns a masker configured by opts, without masks registered
+// unless WithDefaults is given
+//
+// Example: gmask.New(gmask.WithDefaults(), gmask.WithMaxDepth(32))
+func New(opts ...Option) *Masker {
+	o := newOptions(opts)
+
+	m := new(Masker)
+	m.cfg.Store(&config{
+		maskFloat64FuncMap: make(map[string]MaskFloat64Func),
+		maskStringFuncMap:  make(map[string]MaskStringFunc),
+		maskIntFuncMap:     make(map[string]MaskIntFunc),
+		maskUintFuncMap:    make(map[string]MaskUintFunc),
+		maskAnyFuncMap:     make(map[string]MaskAnyFunc),
+
+		maskFloat32FuncMap:    make(map[string]MaskFloat32Func),
+		maskInt8FuncMap:       make(map[string]MaskInt8Func),
+		maskInt16FuncMap:      make(map[string]MaskInt16Func),
+		maskInt32FuncMap:      make(map[string]MaskInt32Func),
+		maskInt64FuncMap:      make(map[string]MaskInt64Func),
+		maskUint8FuncMap:      make(map[string]MaskUint8Func),
+		maskUint16FuncMap:     make(map[string]MaskUint16Func),
+		maskUint32FuncMap:     make(map[string]MaskUint32Func),
+		maskUint64FuncMap:     make(map[string]MaskUint64Func),
+		maskBoolFuncMap:       make(map[string]MaskBoolFunc),
+		maskComplex64FuncMap:  make(map[string]MaskComplex64Func),
+		maskComplex128FuncMap: make(map[string]MaskComplex128Func),
+
+		maskFieldFuncMap: make(map[string]MaskFieldFunc),
+		predicates:       make(map[string]MaskPredicate),
+		kindDefaults:     make(map[reflect.Kind][]string),
+
+		strict:   o.strict,
+		maxDepth: o.maxDepth,
+		fallback: o.fallback,
+		tagName:  o.tagName,
+
+		argNames:   make(map[string][]string),
+		argSchemas: make(map[string]*argSchema),
+		tags:       new(sync.Map),
+	})
+	return o.apply(m)
+}
+
+// Clone returns a masker starting with the masks, settings and policy of m,
+// changing either one afterwards leaves the other untouched
+func (m *Masker) Clone() *Masker {
+	clone := new(Masker)
+	clone.cfg.Store(m.c())
+	clone.policy.Store(m.policy.Load())
+	return clone
+}
+
+// c returns the current configuration, it must not be modified
+func (m *Masker) c() *config {
+	return m.cfg.Load()
+}
+
+// update publishes a copy of the configuration modified by change
+func (m *Masker) update(change func(c *config)) *Masker {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c := m.c().clone()
+	change(c)
+	m.cfg.Store(c)
+	return m
+}
+
+func (c *config) clone() *config {
+	c2 := *c
+	c2.maskFloat64FuncMap = cloneMap(c.maskFloat64FuncMap)
+	c2.maskStringFuncMap = cloneMap(c.maskStringFuncMap)
+	c2.maskIntFuncMap = cloneMap(c.maskIntFuncMap)
+	c2.maskUintFuncMap = cloneMap(c.maskUintFuncMap)
+	c2.maskAnyFuncMap = cloneMap(c.maskAnyFuncMap)
+	c2.maskFloat32FuncMap = cloneMap(c.maskFloat32FuncMap)
+	c2.maskInt8FuncMap = cloneMap(c.maskInt8FuncMap)
+	c2.maskInt16FuncMap = cloneMap(c.maskInt16FuncMap)
+	c2.maskInt32FuncMap = cloneMap(c.maskInt32FuncMap)
+	c2.maskInt64FuncMap = cloneMap(c.maskInt64FuncMap)
+	c2.maskUint8FuncMap = cloneMap(c.maskUint8FuncMap)
+	c2.maskUint16FuncMap = cloneMap(c.maskUint16FuncMap)
+	c2.maskUint32FuncMap = cloneMap(c.maskUint32FuncMap)
+	c2.maskUint64FuncMap = cloneMap(c.maskUint64FuncMap)
+	c2.maskBoolFuncMap = cloneMap(c.maskBoolFuncMap)
+	c2.maskComplex64FuncMap = cloneMap(c.maskComplex64FuncMap)
+	c2.maskComplex128FuncMap = cloneMap(c.maskComplex128FuncMap)
+	c2.maskFieldFuncMap = cloneMap(c.maskFieldFuncMap)
+	c2.predicates = cloneMap(c.predicates)
+	c2.kindDefaults = cloneMap(c.kindDefaults)
+	c2.argNames = cloneMap(c.argNames)
+	c2.argSchemas = cloneMap(c.argSchemas)
+	return &c2
+}
+
+func cloneMap[K comparable, V any](m map[K]V) map[K]V {
+	m2 := make(map[K]V, len(m))
+	for k, v := range m {
+		m2[k] = v
+	}
+	return m2
+}
+
+func (m *Masker) RegMaskStringFunc(maskName string, mask MaskStringFunc) *Masker {
+	return m.update(func(c *config) {
+		c.maskStringFuncMap[maskName] = mask
+	})
+}
+
+func (m *Masker) RegMaskUintFunc(maskName string, mask MaskUintFunc) *Masker {
+	return m.update(func(c *config) {
+		c.maskUintFuncMap[maskName] = mask
+	})
+}
+
+func (m *Masker) RegMaskIntFunc(maskName string, mask MaskIntFunc) *Masker {
+	return m.update(func(c *config) {
+		c.maskIntFuncMap[maskName] = mask
+	})
+}
+
+func (m *Masker) RegMaskFloat64Func(maskName string, mask MaskFloat64Func) *Masker {
+	return m.update(func(c *config) {
+		c.maskFloat64FuncMap[maskName] = mask
+	})
+}
+
+func (m *Masker) RegMaskAnyFunc(maskName string, mask MaskAnyFunc) *Masker {
+	return m.update(func(c *config) {
+		c.maskAnyFuncMap[maskName] = mask
+	})
+}
+
+func (m *Masker) RegMaskFloat32Func(maskName string, mask MaskFloat32Func) *Masker {
+	return m.update(func(c *config) {
+		c.maskFloat32FuncMap[maskName] = mask
+	})
+}
+
+func (m *Masker) RegMaskInt8Func(maskName string, mask MaskInt8Func) *Masker {
+	return m.update(func(c *config) {
+		c.maskInt8FuncMap[maskName] = mask
+	})
+}
+
+func (m *Masker) RegMaskInt16Func(maskName string, mask MaskInt16Func) *Masker {
+	return m.update(func(c *config) {
+		c.maskInt16FuncMap[maskName] = mask
+	})
+}
+
+func (m *Masker) RegMaskInt32Func(maskName string, mask MaskInt32Func) *Masker {
+	return m.update(func(c *config) {
+		c.maskInt32FuncMap[maskName] = mask
+	})
+}
+
+func (m *Masker) RegMaskInt64Func(maskName string, mask MaskInt64Func) *Masker {
+	return m.update(func(c *config) {
+		c.maskInt64FuncMap[maskName] = mask
+	})
+}
+
+func (m *Masker) RegMaskUint8Func(maskName string, mask MaskUint8Func) *Masker {
+	return m.update(func(c *config) {
+		c.maskUint8FuncMap[maskName] = mask
+	})
+}
+
+func (m *Masker) RegMaskUint16Func(maskName string, mask MaskUint16Func) *Masker {
+	return m.update(func(c *config) {
+		c.maskUint16FuncMap[maskName] = mask
+	})
+}
+
+func (m *Masker) RegMaskUint32Func(maskName string, mask MaskUint32Func) *Masker {
+	return m.update(func(c *config) {
+		c.maskUint32FuncMap[maskName] = mask
+	})
+}
+
+func (m *Masker) RegMaskUint64Func(maskName string, mask MaskUint64Func) *Masker {
+	return m.update(func(c *config) {
+		c.maskUint64FuncMap[maskName] = mask
+	})
+}
+
+func (m *Masker) RegMaskBoolFunc(maskName string, mask MaskBoolFunc) *Masker {
+	return m.update(func(c *config) {
+		c.maskBoolFuncMap[maskName] = mask
+	})
+}
+
+func (m *Masker) RegMaskComplex64Func(maskName string, mask MaskComplex64Func) *Masker {
+	return m.update(func(c *config) {
+		c.maskComplex64FuncMap[maskName] = mask
+	})
+}
+
+func (m *Masker) RegMaskComplex128Func(maskName string, mask MaskComplex128Func) *Masker {
+	return m.update(func(c *config) {
+		c.maskComplex128FuncMap[maskName] = mask
+	})
+}
+
+// SetKeepUnexported makes the masked copy of a struct start from a shallow
+// copy of the original, so unexported fields are kept instead of zeroed,
+// exported fields are still masked as usual.
+func (m *Masker) SetKeepUnexported(keep bool) *Masker {
+	return m.update(func(c *config) {
+		c.keepUnexported = keep
+	})
+}
+
+// SetMaxDepth limits how many nested values a single Mask call may walk
+// through, masking a deeper value returns an error. 0 means unlimited.
+func (m *Masker) SetMaxDepth(depth int) *Masker {
+	return m.update(func(c *config) {
+		c.maxDepth = depth
+	})
+}
+
+// SetFallback makes a failed mask replace the value with the result of
+// fallback, given the arguments of the failed mask, instead of aborting,
+// the masking goes on and every failure is returned joined together along
+// with the masked value.
+// MaskZero, MaskRedacted and MaskFixed are ready to use fallbacks,
+// nil restores aborting on the first failure.
+func (m *Masker) SetFallback(fallback MaskAnyFunc) *Masker {
+	return m.update(func(c *config) {
+		c.fallback = fallback
+	})
+}
+
+// SetStrict switches strict mode, which is on for new maskers.
+// In strict mode a tag naming an unregistered mask, or a mask not
+// registered for the kind of the value, is an error. Otherwise such
+// values are left as they are.
+func (m *Masker) SetStrict(strict bool) *Masker {
+	return m.update(func(c *config) {
+		c.strict = strict
+	})
+}
+
+func (m *Masker) Mask(target any) (ret any, err error) {
+	return m.MaskContext(context.Background(), target)
+}
+
+// MaskContext is like Mask, ctx is handed to the field masks through
+// FieldContext so they can reach request scoped values and honor
+// cancellation. Masking stops with the error of ctx once it is done,
+// a fallback never replaces that error.
+func (m *Masker) MaskContext(ctx context.Context, target any) (ret any, err error) {
+	s := m.newState(ctx, reflect.TypeOf(target))
+	rv, err := m.mask(s, reflect.ValueOf(target), reflect.Value{})
+	if err != nil {
+		return ret, err
+	}
+
+	return rv.Interface(), errors.Join(s.errs...)
+}
+
+func (m *Masker) Float64(value float64, tag ...string) (float64, error) {
+	return m.c().Float64(value, tag...)
+}
+
+func (m *Masker) String(value string, tag ...string) (string, error) {
+	return m.c().String(value, tag...)
+}
+
+func (m *Masker) Int(value int, tag ...string) (int, error) {
+	return m.c().Int(value, tag...)
+}
+
+func (m *Masker) Uint(value uint, tag ...string) (uint, error) {
+	return m.c().Uint(value, tag...)
+}
+
+func (m *Masker) Float32(value float32, tag ...string) (float32, error) {
+	return m.c().Float32(value, tag...)
+}
+
+func (m *Masker) Int8(value int8, tag ...string) (int8, error) {
+	return m.c().Int8(value, tag...)
+}
+
+func (m *Masker) Int16(value int16, tag ...string) (int16, error) {
+	return m.c().Int16(value, tag...)
+}
+
+func (m *Masker) Int32(value int32, tag ...string) (int32, error) {
+	return m.c().Int32(value, tag...)
+}
+
+func (m *Masker) Int64(value int64, tag ...string) (int64, error) {
+	return m.c().Int64(value, tag...)
+}
+
+func (m *Masker) Uint8(value uint8, tag ...string) (uint8, error) {
+	return m.c().Uint8(value, tag...)
+}
+
+func (m *Masker) Uint16(value uint16, tag ...string) (uint16, error) {
+	return m.c().Uint16(value, tag...)
+}
+
+func (m *Masker) Uint32(value uint32, tag ...string) (uint32, error) {
+	return m.c().Uint32(value, tag...)
+}
+
+func (m *Masker) Uint64(value uint64, tag ...string) (uint64, error) {
+	return m.c().Uint64(value, tag...)
+}
+
+func (m *Masker) Bool(value bool, tag ...string) (bool, error) {
+	return m.c().Bool(value, tag...)
+}
+
+func (m *Masker) Complex64(value complex64, tag ...string) (complex64, error) {
+	return m.c().Complex64(value, tag...)
+}
+
+func (m *Masker) Complex128(value complex128, tag ...string) (complex128, error) {
+	return m.c().Complex128(value, tag...)
+}
+
+func (m *Masker) Any(value any, tag ...string) (hit bool, output any, err error) {
+	return m.c().Any(value, tag...)
+}
+
+func (c *config) Float64(value float64, tag ...string) (float64, error) {
+	if len(tag) == 0 || len(tag[0]) == 0 {
+		return value, nil
+	}
+
+	if maskFunc, exist := c.maskFloat64FuncMap[tag[0]]; exist {
+		return maskFunc(value, tag[1:]...)
+	}
+
+	if ok, v, err := c.Any(value, tag...); ok {
+		return v.(float64), err
+	}
+
+	return value, c.missing(tag[0], "float64")
+}
+
+func (c *config) String(value string, tag ...string) (string, error) {
+	if len(tag) == 0 || len(tag[0]) == 0 {
+		return value, nil
+	}
+
+	if maskFunc, exist := c.maskStringFuncMap[tag[0]]; exist {
+		return maskFunc(value, tag[1:]...)
+	}
+
+	if ok, v, err := c.Any(value, tag...); ok {
+		return v.(string), err
+	}
+
+	return value, c.missing(tag[0], "string")
+}
+
+func (c *config) Int(value int, tag ...string) (int, error) {
+	if len(tag) == 0 || len(tag[0]) == 0 {
+		return value, nil
+	}
+
+	if maskFunc, exist := c.maskIntFuncMap[tag[0]]; exist {
+		return maskFunc(value, tag[1:]...)
+	}
+
+	if ok, v, err := c.Any(value, tag...); ok {
+		return v.(int), err
+	}
+
+	return value, c.missing(tag[0], "int")
+}
+
+func (c *config) Uint(value uint, tag ...string) (uint, error) {
+	if len(tag) == 0 || len(tag[0]) == 0 {
+		return value, nil
+	}
+
+	if maskFunc, exist := c.maskUintFuncMap[tag[0]]; exist {
+		return maskFunc(value, tag[1:]...)
+	}
+
+	if ok, v, err := c.Any(value, tag...); ok {
+		return v.(uint), err
+	}
+
+	return value, c.missing(tag[0], "uint")
+}
+
+func (c *config) Float32(value float32, tag ...string) (float32, error) {
+	if len(tag) == 0 || len(tag[0]) == 0 {
+		return value, nil
+	}
+
+	if maskFunc, exist := c.maskFloat32FuncMap[tag[0]]; exist {
+		return maskFunc(value, tag[1:]...)
+	}
+
+	if ok, v, err := maskFloatWide(c, value, tag...); ok {
+		return v, err
+	}
+
+	if ok, v, err := c.Any(value, tag...); ok {
+		return v.(float32), err
+	}
+
+	return value, c.missing(tag[0], "float32")
+}
+
+func (c *config) Int8(value int8, tag ...string) (int8, error) {
+	if len(tag) == 0 || len(tag[0]) == 0 {
+		return value, nil
+	}
+
+	if maskFunc, exist := c.maskInt8FuncMap[tag[0]]; exist {
+		return maskFunc(value, tag[1:]...)
+	}
+
+	if ok, v, err := maskIntWide(c, value, tag...); ok {
+		return v, err
+	}
+
+	if ok, v, err := c.Any(value, tag...); ok {
+		return v.(int8), err
+	}
+
+	return value, c.missing(tag[0], "int8")
+}
+
+func (c *config) Int16(value int16, tag ...string) (int16, error) {
+	if len(tag) == 0 || len(tag[0]) == 0 {
+		return value, nil
+	}
+
+	if maskFunc, exist := c.maskInt16FuncMap[tag[0]]; exist {
+		return maskFunc(value, tag[1:]...)
+	}
+
+	if ok, v, err := maskIntWide(c, value, tag...); ok {
+		return v, err
+	}
+
+	if ok, v, err := c.Any(value, tag...); ok {
+		return v.(int16), err
+	}
+
+	return value, c.missing(tag[0], "int16")
+}
+
+func (c *config) Int32(value int32, tag ...string) (int32, error) {
+	if len(tag) == 0 || len(tag[0]) == 0 {
+		return value, nil
+	}
+
+	if maskFunc, exist := c.maskInt32FuncMap[tag[0]]; exist {
+		return maskFunc(value, tag[1:]...)
+	}
+
+	if ok, v, err := maskIntWide(c, value, tag...); ok {
+		return v, err
+	}
+
+	if ok, v, err := c.Any(value, tag...); ok {
+		return v.(int32), err
+	}
+
+	return value, c.missing(tag[0], "int32")
+}
+
+func (c *config) Int64(value int64, tag ...string) (int64, error) {
+	if len(tag) == 0 || len(tag[0]) == 0 {
+		return value, nil
+	}
+
+	if maskFunc, exist := c.maskInt64FuncMap[tag[0]]; exist {
+		return maskFunc(value, tag[1:]...)
+	}
+
+	if ok, v, err := maskIntWide(c, value, tag...); ok {
+		return v, err
+	}
+
+	if ok, v, err := c.Any(value, tag...); ok {
+		return v.(int64), err
+	}
+
+	return value, c.missing(tag[0], "int64")
+}
+
+func (c *config) Uint8(value uint8, tag ...string) (uint8, error) {
+	if len(tag) == 0 || len(tag[0]) == 0 {
+		return value, nil
+	}
+
+	if maskFunc, exist := c.maskUint8FuncMap[tag[0]]; exist {
+		return maskFunc(value, tag[1:]...)
+	}
+
+	if ok, v, err := maskUintWide(c, value, tag...); ok {
+		return v, err
+	}
+
+	if ok, v, err := c.Any(value, tag...); ok {
+		return v.(uint8), err
+	}
+
+	return value, c.missing(tag[0], "uint8")
+}
+
+func (c *config) Uint16(value uint16, tag ...string) (uint16, error) {
+	if len(tag) == 0 || len(tag[0]) == 0 {
+		return value, nil
+	}
+
+	if maskFunc, exist := c.maskUint16FuncMap[tag[0]]; exist {
+		return maskFunc(value, tag[1:]...)
+	}
+
+	if ok, v, err := maskUintWide(c, value, tag...); ok {
+		return v, err
+	}
+
+	if ok, v, err := c.Any(value, tag...); ok {
+		return v.(uint16), err
+	}
+
+	return value, c.missing(tag[0], "uint16")
+}
+
+func (c *config) Uint32(value uint32, tag ...string) (uint32, error) {
+	if len(tag) == 0 || len(tag[0]) == 0 {
+		return value, nil
+	}
+
+	if maskFunc, exist := c.maskUint32FuncMap[tag[0]]; exist {
+		return maskFunc(value, tag[1:]...)
+	}
+
+	if ok, v, err := maskUintWide(c, value, tag...); ok {
+		return v, err
+	}
+
+	if ok, v, err := c.Any(value, tag...); ok {
+		return v.(uint32), err
+	}
+
+	return value, c.missing(tag[0], "uint32")
+}
+
+func (c *config) Uint64(value uint64, tag ...string) (uint64, error) {
+	if len(tag) == 0 || len(tag[0]) == 0 {
+		return value, nil
+	}
+
+	if maskFunc, exist := c.maskUint64FuncMap[tag[0]]; exist {
+		return maskFunc(value, tag[1:]...)
+	}
+
+	if ok, v, err := maskUintWide(c, value, tag...); ok {
+		return v, err
+	}
+
+	if ok, v, err := c.Any(value, tag...); ok {
+		return v.(uint64), err
+	}
+
+	return value, c.missing(tag[0], "uint64")
+}
+
+func (c *config) Bool(value bool, tag ...string) (bool, error) {
+	if len(tag) == 0 || len(tag[0]) == 0 {
+		return value, nil
+	}
+
+	if maskFunc, exist := c.maskBoolFuncMap[tag[0]]; exist {
+		return maskFunc(value, tag[1:]...)
+	}
+
+	if ok, v, err := c.Any(value, tag...); ok {
+		return v.(bool), err
+	}
+
+	return value, c.missing(tag[0], "bool")
+}
+
+func (c *config) Complex64(value complex64, tag ...string) (complex64, error) {
+	if len(tag) == 0 || len(tag[0]) == 0 {
+		return value, nil
+	}
+
+	if maskFunc, exist := c.maskComplex64FuncMap[tag[0]]; exist {
+		return maskFunc(value, tag[1:]...)
+	}
+
+	if ok, v, err := c.Any(value, tag...); ok {
+		return v.(complex64), err
+	}
+
+	return value, c.missing(tag[0], "complex64")
+}
+
+func (c *config) Complex128(value complex128, tag ...string) (complex128, error) {
+	if len(tag) == 0 || len(tag[0]) == 0 {
+		return value, nil
+	}
+
+	if maskFunc, exist := c.maskComplex128FuncMap[tag[0]]; exist {
+		return maskFunc(value, tag[1:]...)
+	}
+
+	if ok, v, err := c.Any(value, tag...); ok {
+		return v.(complex128), err
+	}
+
+	return value, c.missing(tag[0], "complex128")
+}
+
+func (c *config) Any(value any, tag ...string) (hit bool, output any, err error) {
+	if len(tag) == 0 || len(tag[0]) == 0 {
+		return false, value, nil
+	}
+
+	if maskFunc, exist := c.maskAnyFuncMap[tag[0]]; exist {
+		output, err = maskFunc(value, tag[1:]...)
+		return true, output, err
+	}
+
+	return false, value, nil
+}
+
+// missing reports in strict mode that no mask named maskName
+// can mask a value of the given type
+func (c *config) missing(maskName string, typ string) error {
+	if !c.strict {
+		return nil
+	}
+
+	maskName = strings.TrimPrefix(maskName, eachPrefix)
+	if c.registered(maskName) {
+		return fmt.Errorf("%w: %s can not mask %s", ErrUnsupportedKind, maskName, typ)
+	}
+	return fmt.Errorf("%w: %s", ErrUnknownStrategy, maskName)
+}
+
+// registered reports whether a mask is registered under the name for any kind
+func (c *config) registered(maskName string) bool {
+	return has(c.maskFloat64FuncMap, maskName) ||
+		has(c.maskStringFuncMap, maskName) ||
+		has(c.maskIntFuncMap, maskName) ||
+		has(c.maskUintFuncMap, maskName) ||
+		has(c.maskAnyFuncMap, maskName) ||
+		has(c.maskFloat32FuncMap, maskName) ||
+		has(c.maskInt8FuncMap, maskName) ||
+		has(c.maskInt16FuncMap, maskName) ||
+		has(c.maskInt32FuncMap, maskName) ||
+		has(c.maskInt64FuncMap, maskName) ||
+		has(c.maskUint8FuncMap, maskName) ||
+		has(c.maskUint16FuncMap, maskName) ||
+		has(c.maskUint32FuncMap, maskName) ||
+		has(c.maskUint64FuncMap, maskName) ||
+		has(c.maskBoolFuncMap, maskName) ||
+		has(c.maskComplex64FuncMap, maskName) ||
+		has(c.maskComplex128FuncMap, maskName) ||
+		has(c.maskFieldFuncMap, maskName)
+}
+
+func has[F any](funcMap map[string]F, maskName string) bool {
+	_, exist := funcMap[maskName]
+	return exist
+}
+
+// maskIntWide masks a sized signed integer with the int registry when its
+// exact kind registry has no such mask, refusing results that would not fit.
+func maskIntWide[T int8 | int16 | int32 | int64](c *config, value T, tag ...string) (hit bool, output T, err error) {
+	maskFunc, exist := c.maskIntFuncMap[tag[0]]
+	if !exist {
+		return false, value, nil
+	}
+
+	if int64(int(value)) != int64(value) {
+		return true, value, fmt.Errorf("%d overflows int", value)
+	}
+	v, err := maskFunc(int(value), tag[1:]...)
+	if err != nil {
+		return true, value, err
+	}
+	if int64(T(v)) != int64(v) {
+		return true, value, fmt.Errorf("%d overflows %T", v, value)
+	}
+
+	return true, T(v), nil
 }
 
-func New() *Masker {
-	return &Masker{
-		maskFloat64FuncMap: make(map[string]MaskFloat64Func),
-		maskStringFuncMap:  make(map[string]MaskStringFunc),
-		maskIntFuncMap:     make(map[string]MaskIntFunc),
-		maskUintFuncMap:    make(map[string]MaskUintFunc),
-		maskAnyFuncMap:     make(map[string]MaskAnyFunc),
+// maskUintWide is the unsigned counterpart of maskIntWide.
+func maskUintWide[T uint8 | uint16 | uint32 | uint64](c *config, value T, tag ...string) (hit bool, output T, err error) {
+	maskFunc, exist := c.maskUintFuncMap[tag[0]]
+	if !exist {
+		return false, value, nil
+	}
+
+	if uint64(uint(value)) != uint64(value) {
+		return true, value, fmt.Errorf("%d overflows uint", value)
 	}
+	v, err := maskFunc(uint(value), tag[1:]...)
+	if err != nil {
+		return true, value, err
+	}
+	if uint64(T(v)) != uint64(v) {
+		return true, value, fmt.Errorf("%d overflows %T", v, value)
+	}
+
+	return true, T(v), nil
 }
 
-func (m *Masker) RegMaskStringFunc(maskName string, mask MaskStringFunc) *Masker {
-	m.maskStringFuncMap[maskName] = mask
-	return m
+// maskFloatWide masks a float32 with the float64 registry when no float32
+// mask is registered under the name.
+func maskFloatWide(c *config, value float32, tag ...string) (hit bool, output float32, err error) {
+	maskFunc, exist := c.maskFloat64FuncMap[tag[0]]
+	if !exist {
+		return false, value, nil
+	}
+
+	v, err := maskFunc(float64(value), tag[1:]...)
+	if err != nil {
+		return true, value, err
+	}
+	if math.Abs(v) > math.MaxFloat32 && !math.IsInf(v, 0) {
+		return true, value, fmt.Errorf("%g overflows float32", v)
+	}
+
+	return true, float32(v), nil
 }
 
-func (m *Masker) RegMaskUintFunc(maskName string, mask MaskUintFunc) *Masker {
-	m.maskUintFuncMap[maskName] = mask
-	return m
+// maskState is the bookkeeping of a single Mask call
+type maskState struct {
+	// visited maps the pointers, maps and slices met so far to their
+	// masked copy, which breaks cycles and keeps aliases pointing at the same copy
+	visited map[visitKey]reflect.Value
+	depth   int
+	// path holds the segments leading to the value being masked,
+	// starting with the name of the root type
+	path []string
+	// errs collects the errors replaced by the fallback
+	errs []error
+
+	ctx context.Context
+	// view selects the rule of tags with views
+	view string
+	// policy is the policy of the masker when the walk started
+	policy *typeRules
+	// policies holds the policies of the structs being walked
+	policies []policyFrame
+	// cfg is the configuration of the masker when the walk started
+	cfg *config
+	// field is the struct field being masked and parent the struct holding it
+	field  reflect.StructField
+	parent reflect.Value
 }
 
-func (m *Masker) RegMaskIntFunc(maskName string, mask MaskIntFunc) *Masker {
-	m.maskIntFuncMap[maskName] = mask
-	return m
+// visitKey also holds the tag, the same pointer reached through fields
+// with different tags must be masked separately. Slices are keyed by their
+// data pointer and length, subslices sharing it are masked separately.
+type visitKey struct {
+	ptr uintptr
+	len int
+	typ reflect.Type
+	tag string
 }
 
-func (m *Masker) RegMaskFloat64Func(maskName string, mask MaskFloat64Func) *Masker {
-	m.maskFloat64FuncMap[maskName] = mask
-	return m
+func (m *Masker) newState(ctx context.Context, root reflect.Type) *maskState {
+	s := &maskState{
+		visited: make(map[visitKey]reflect.Value),
+		ctx:     ctx,
+		view:    ViewFromContext(ctx),
+		cfg:     m.c(),
+		policy:  m.policy.Load(),
+	}
+	for root != nil && root.Kind() == reflect.Ptr {
+		root = root.Elem()
+	}
+	if root != nil {
+		s.push(root.Name())
+	}
+	return s
 }
 
-func (m *Masker) RegMaskAnyFunc(maskName string, mask MaskAnyFunc) *Masker {
-	m.maskAnyFuncMap[maskName] = mask
-	return m
+// push appends a segment to the path, either a field name or an index
+// or key written in brackets
+func (s *maskState) push(segment string) {
+	s.path = append(s.path, segment)
 }
 
-func (m *Masker) Mask(target any) (ret any, err error) {
-	rv, err := m.mask(reflect.ValueOf(target), reflect.Value{})
-	if err != nil {
-		return ret, err
-	}
+func (s *maskState) pop() {
+	s.path = s.path[:len(s.path)-1]
+}
 
-	return rv.Interface(), nil
+func (s *maskState) pathString() string {
+	return joinPath(s.path)
 }
 
-func (m *Masker) Float64(value float64, tag ...string) (float64, error) {
-	if len(tag) == 0 || len(tag[0]) == 0 {
-		return value, nil
+// joinPath writes segments as a path, field names are separated by dots
+func joinPath(segments []string) string {
+	var b strings.Builder
+	for i, segment := range segments {
+		if i != 0 && !strings.HasPrefix(segment, "[") {
+			b.WriteByte('.')
+		}
+		b.WriteString(segment)
 	}
+	return strings.TrimPrefix(b.String(), ".")
+}
 
-	if maskFunc, exist := m.maskFloat64FuncMap[tag[0]]; exist {
-		return maskFunc(value, tag[1:]...)
+// wrap turns err into a *MaskError at the current path,
+// errors that already carry a path are returned as is
+func (s *maskState) wrap(err error, tag []string) error {
+	var maskErr *MaskError
+	if errors.As(err, &maskErr) {
+		return err
 	}
 
-	if ok, v, err := m.Any(value, tag...); ok {
-		return v.(float64), err
+	maskErr = &MaskError{
+		Path: s.pathString(),
+		Tag:  strings.Join(tag, ","),
+		Err:  err,
+	}
+	if len(tag) != 0 {
+		maskErr.Strategy = strings.TrimPrefix(tag[0], eachPrefix)
 	}
+	return maskErr
+}
 
-	return value, nil
+// enter steps one level deeper into the masked value
+func (m *Masker) enter(s *maskState) error {
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+	if s.cfg.maxDepth > 0 && s.depth >= s.cfg.maxDepth {
+		return fmt.Errorf("%w: %d", ErrMaxDepth, s.cfg.maxDepth)
+	}
+	s.depth++
+	return nil
 }
 
-func (m *Masker) String(value string, tag ...string) (string, error) {
-	if len(tag) == 0 || len(tag[0]) == 0 {
-		return value, nil
+func (s *maskState) leave() {
+	s.depth--
+}
+
+func (m *Masker) mask(s *maskState, rv reflect.Value, mp reflect.Value, tag ...string) (reflect.Value, error) {
+	rv2, err := m.maskStage(s, rv, mp, tag...)
+	if err != nil {
+		return m.fail(s, rv, mp, err, tag)
 	}
 
-	if maskFunc, exist := m.maskStringFuncMap[tag[0]]; exist {
-		return maskFunc(value, tag[1:]...)
+	return rv2, nil
+}
+
+// maskStage masks rv with a single mask, unlike mask it never falls back
+func (m *Masker) maskStage(s *maskState, rv reflect.Value, mp reflect.Value, tag ...string) (reflect.Value, error) {
+	if err := m.enter(s); err != nil {
+		return reflect.Value{}, s.wrap(err, tag)
 	}
+	defer s.leave()
 
-	if ok, v, err := m.Any(value, tag...); ok {
-		return v.(string), err
+	rv2, err := m.maskValue(s, rv, mp, tag...)
+	if err != nil {
+		return reflect.Value{}, s.wrap(err, tag)
 	}
 
-	return value, nil
+	return rv2, nil
 }
 
-func (m *Masker) Int(value int, tag ...string) (int, error) {
-	if len(tag) == 0 || len(tag[0]) == 0 {
-		return value, nil
+// maskRule masks rv with the pipelines of r in order until one succeeds
+func (m *Masker) maskRule(s *maskState, rv reflect.Value, mp reflect.Value, r rule) (reflect.Value, error) {
+	if len(r) == 1 && len(r[0]) == 1 {
+		return m.mask(s, rv, mp, r[0][0]...)
 	}
 
-	if maskFunc, exist := m.maskIntFuncMap[tag[0]]; exist {
-		return maskFunc(value, tag[1:]...)
+	// a failed alternative may leave the segments it pushed on the path
+	depth := len(s.path)
+	var errs []error
+	for _, p := range r {
+		rv2, err := m.maskPipeline(s, rv, p)
+		if err != nil {
+			errs = append(errs, err)
+			s.path = s.path[:depth]
+			continue
+		}
+
+		if mp.IsValid() {
+			mp.Set(rv2)
+			return mp, nil
+		}
+		return rv2, nil
 	}
 
-	if ok, v, err := m.Any(value, tag...); ok {
-		return v.(int), err
+	return m.fail(s, rv, mp, errors.Join(errs...), nil)
+}
+
+// maskPipeline masks rv with every mask of p, each one masking the result
+// of the previous one
+func (m *Masker) maskPipeline(s *maskState, rv reflect.Value, p pipeline) (reflect.Value, error) {
+	for _, st := range p {
+		rv2, err := m.maskStage(s, rv, reflect.New(rv.Type()).Elem(), st...)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		rv = rv2
 	}
 
-	return value, nil
+	return rv, nil
 }
 
-func (m *Masker) Uint(value uint, tag ...string) (uint, error) {
-	if len(tag) == 0 || len(tag[0]) == 0 {
-		return value, nil
+// fail handles err raised while masking rv, without a fallback it is
+// returned at once, otherwise rv is replaced with the fallback and err is
+// kept until the walk ends
+func (m *Masker) fail(s *maskState, rv reflect.Value, mp reflect.Value, err error, tag []string) (reflect.Value, error) {
+	err = s.wrap(err, tag)
+	if s.cfg.fallback == nil || s.ctx.Err() != nil {
+		return reflect.Value{}, err
 	}
 
-	if maskFunc, exist := m.maskUintFuncMap[tag[0]]; exist {
-		return maskFunc(value, tag[1:]...)
+	// like any mask the fallback is given the arguments of the failed mask
+	var args []string
+	if len(tag) != 0 {
+		args = tag[1:]
+	}
+	v, fallbackErr := s.cfg.fallback(rv.Interface(), args...)
+	if fallbackErr != nil {
+		return reflect.Value{}, errors.Join(err, fallbackErr)
 	}
+	s.errs = append(s.errs, err)
 
-	if ok, v, err := m.Any(value, tag...); ok {
-		return v.(uint), err
+	rt := rv.Type()
+	rv2 := reflect.Zero(rt)
+	if fv := reflect.ValueOf(v); convertible(fv, rt) {
+		rv2 = fv.Convert(rt)
+	}
+	if mp.IsValid() {
+		mp.Set(rv2)
+		return mp, nil
 	}
 
-	return value, nil
+	return rv2, nil
 }
 
-func (m *Masker) Any(value any, tag ...string) (hit bool, output any, err error) {
-	if len(tag) == 0 || len(tag[0]) == 0 {
-		return false, value, nil
+func (m *Masker) maskValue(s *maskState, rv reflect.Value, mp reflect.Value, tag ...string) (reflect.Value, error) {
+	tag, zero := s.cfg.denyTag(rv.Type(), tag)
+	if zero {
+		if mp.IsValid() {
+			mp.Set(reflect.Zero(rv.Type()))
+			return mp, nil
+		}
+		return reflect.Zero(rv.Type()), nil
 	}
 
-	if maskFunc, exist := m.maskAnyFuncMap[tag[0]]; exist {
-		output, err = maskFunc(value, tag[1:]...)
-		return true, output, err
+	if ok, v, err := m.maskField(s, rv, mp, tag...); ok {
+		return v, err
 	}
 
-	return false, value, nil
-}
+	switch rv.Type().Kind() {
+	case reflect.Struct, reflect.Array, reflect.Slice, reflect.Map:
+		if ok, v, err := s.cfg.maskWhole(rv, mp, tag...); ok {
+			return v, err
+		}
+	}
 
-func (m *Masker) mask(rv reflect.Value, mp reflect.Value, tag ...string) (reflect.Value, error) {
-	//if ok, v, err := m.maskAnyValue(tag, rv); ok {
-	//	return v, err
-	//}
 	switch rv.Type().Kind() {
 	case reflect.Interface:
-		return m.maskInterface(rv, mp, tag...)
+		return m.maskInterface(s, rv, mp, tag...)
 	case reflect.Ptr:
-		return m.maskPtr(rv, mp, tag...)
+		return m.maskPtr(s, rv, mp, tag...)
 	case reflect.Struct:
-		return m.maskStruct(rv, mp)
+		if len(tag) != 0 && len(tag[0]) != 0 {
+			if err := s.cfg.missing(tag[0], rv.Type().String()); err != nil {
+				return reflect.Value{}, err
+			}
+		}
+		return m.maskStruct(s, rv, mp)
 	case reflect.Array:
-		return m.maskSlice(rv, mp, tag...)
+		return m.maskSlice(s, rv, mp, eachTag(tag)...)
 	case reflect.Slice:
 		if rv.IsNil() {
 			return reflect.Zero(rv.Type()), nil
 		}
-		return m.maskSlice(rv, mp, tag...)
-	//case reflect.Map:
-	//	return m.maskMap(rv, mp, tag)
+		if rv.Type().Elem().Kind() == reflect.Uint8 && !isEachTag(tag) {
+			return s.cfg.maskBytes(rv, mp, tag...)
+		}
+		return m.maskSlice(s, rv, mp, eachTag(tag)...)
+	case reflect.Map:
+		return m.maskMap(s, rv, mp, eachTag(tag)...)
+	default:
+		return s.cfg.maskScalar(rv, mp, tag...)
+	}
+}
+
+// maskScalar masks a value which holds no other values
+func (c *config) maskScalar(rv reflect.Value, mp reflect.Value, tag ...string) (reflect.Value, error) {
+	switch rv.Type().Kind() {
 	case reflect.Float32, reflect.Float64:
-		return m.maskFloat(rv, mp, tag...)
+		return c.maskFloat(rv, mp, tag...)
 	case reflect.String:
-		return m.maskString(rv, mp, tag...)
+		return c.maskString(rv, mp, tag...)
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return m.maskInt(rv, mp, tag...)
+		return c.maskInt(rv, mp, tag...)
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		return m.maskUint(rv, mp, tag...)
+		return c.maskUint(rv, mp, tag...)
+	case reflect.Bool:
+		return c.maskBool(rv, mp, tag...)
+	case reflect.Complex64, reflect.Complex128:
+		return c.maskComplex(rv, mp, tag...)
 	default:
+		if len(tag) != 0 && len(tag[0]) != 0 {
+			if err := c.missing(tag[0], rv.Type().String()); err != nil {
+				return reflect.Value{}, err
+			}
+		}
 		if mp.IsValid() {
 			mp.Set(rv)
 			return mp, nil
@@ -182,13 +1144,76 @@ func (m *Masker) mask(rv reflect.Value, mp reflect.Value, tag ...string) (reflec
 	}
 }
 
-func (m *Masker) maskInterface(rv reflect.Value, _ reflect.Value, tag ...string) (reflect.Value, error) {
+// maskWhole replaces a struct, array, slice or map as a whole with the
+// any mask named by the tag, unless the tag is prefixed with each:
+func (c *config) maskWhole(rv reflect.Value, mp reflect.Value, tag ...string) (hit bool, output reflect.Value, err error) {
+	if len(tag) == 0 || len(tag[0]) == 0 || isEachTag(tag) {
+		return false, rv, nil
+	}
+
+	hit, v, err := c.Any(rv.Interface(), tag...)
+	if !hit {
+		return false, rv, nil
+	}
+	if err != nil {
+		return true, reflect.Value{}, err
+	}
+
+	rv2, err := anyResult(v, rv.Type(), tag[0])
+	if err != nil {
+		return true, reflect.Value{}, err
+	}
+	if mp.IsValid() {
+		mp.Set(rv2)
+		return true, mp, nil
+	}
+
+	return true, rv2, nil
+}
+
+// anyResult turns the result of an any mask back into a value of type rt,
+// nil is the zero value, an interface type takes any value implementing it
+// and other types are refused unless convertible
+func anyResult(v any, rt reflect.Type, maskName string) (reflect.Value, error) {
+	rv := reflect.ValueOf(v)
+	switch {
+	case !rv.IsValid():
+		return reflect.Zero(rt), nil
+	case rv.Type() == rt:
+		return rv, nil
+	case rt.Kind() == reflect.Interface && rv.Type().Implements(rt):
+		return rv.Convert(rt), nil
+	case convertible(rv, rt):
+		return rv.Convert(rt), nil
+	default:
+		return reflect.Value{}, fmt.Errorf("%w: %s turns %s into %s", ErrUnsupportedKind, maskName, rt, rv.Type())
+	}
+}
+
+func isEachTag(tag []string) bool {
+	return len(tag) != 0 && strings.HasPrefix(tag[0], eachPrefix)
+}
+
+// eachTag strips the each: prefix from the tag of a container,
+// the remaining tag is applied to every element
+func eachTag(tag []string) []string {
+	if !isEachTag(tag) {
+		return tag
+	}
+
+	each := make([]string, len(tag))
+	copy(each, tag)
+	each[0] = strings.TrimPrefix(each[0], eachPrefix)
+	return each
+}
+
+func (m *Masker) maskInterface(s *maskState, rv reflect.Value, _ reflect.Value, tag ...string) (reflect.Value, error) {
 	if rv.IsNil() {
 		return reflect.Zero(rv.Type()), nil
 	}
 
 	mp := reflect.New(rv.Type()).Elem()
-	rv2, err := m.mask(reflect.ValueOf(rv.Interface()), reflect.Value{}, tag...)
+	rv2, err := m.mask(s, reflect.ValueOf(rv.Interface()), reflect.Value{}, tag...)
 	if err != nil {
 		return reflect.Value{}, err
 	}
@@ -197,13 +1222,19 @@ func (m *Masker) maskInterface(rv reflect.Value, _ reflect.Value, tag ...string)
 	return mp, nil
 }
 
-func (m *Masker) maskPtr(rv reflect.Value, _ reflect.Value, tag ...string) (reflect.Value, error) {
+func (m *Masker) maskPtr(s *maskState, rv reflect.Value, _ reflect.Value, tag ...string) (reflect.Value, error) {
 	if rv.IsNil() {
 		return reflect.Zero(rv.Type()), nil
 	}
 
+	key := visitKey{ptr: rv.Pointer(), typ: rv.Type(), tag: strings.Join(tag, ",")}
+	if mp, visited := s.visited[key]; visited {
+		return mp, nil
+	}
+
 	mp := reflect.New(rv.Type().Elem())
-	rv2, err := m.mask(rv.Elem(), mp.Elem(), tag...)
+	s.visited[key] = mp
+	rv2, err := m.mask(s, rv.Elem(), mp.Elem(), tag...)
 	if err != nil {
 		return reflect.Value{}, err
 	}
@@ -212,7 +1243,7 @@ func (m *Masker) maskPtr(rv reflect.Value, _ reflect.Value, tag ...string) (refl
 	return mp, nil
 }
 
-func (m *Masker) maskStruct(rv reflect.Value, mp reflect.Value) (reflect.Value, error) {
+func (m *Masker) maskStruct(s *maskState, rv reflect.Value, mp reflect.Value) (reflect.Value, error) {
 	if rv.IsZero() {
 		return reflect.Zero(rv.Type()), nil
 	}
@@ -221,76 +1252,124 @@ func (m *Masker) maskStruct(rv reflect.Value, mp reflect.Value) (reflect.Value,
 	if !mp.IsValid() {
 		mp = reflect.New(rt).Elem()
 	}
+	if s.cfg.keepUnexported {
+		mp.Set(rv)
+	}
 
+	parent, outer := s.parent, s.field
+	defer func() { s.parent, s.field = parent, outer }()
+	defer s.enterPolicy(rt)()
 	for i := 0; i < rt.NumField(); i++ {
 		field := rt.Field(i)
-		// skip private field
+		// skip private field, it is either zero or shallow copied above
 		if field.PkgPath != "" {
 			continue
 		}
 
-		args := strings.Split(field.Tag.Get(tagName), ",")
-		switch field.Type.Kind() {
-		case reflect.String:
-			s, err := m.String(rv.Field(i).String(), args...)
-			if err != nil {
-				return reflect.Value{}, err
-			}
-			mp.Field(i).SetString(s)
+		s.push(field.Name)
+		s.parent, s.field = rv, field
+		var rvf reflect.Value
+		r, err := m.fieldRule(s, field)
+		switch {
+		case err != nil:
+			rvf, err = m.fail(s, rv.Field(i), mp.Field(i), err, []string{m.fieldTag(s, field)})
+		case r == nil:
+			rvf = reflect.Zero(field.Type)
 		default:
-			rvf, err := m.mask(rv.Field(i), mp.Field(i), args...)
-			if err != nil {
-				return reflect.Value{}, err
-			}
-			mp.Field(i).Set(rvf)
+			rvf, err = m.maskRule(s, rv.Field(i), mp.Field(i), r)
 		}
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		mp.Field(i).Set(rvf)
+		s.pop()
 	}
 
 	return mp, nil
 }
 
-func (m *Masker) maskSlice(rv reflect.Value, mp reflect.Value, tag ...string) (reflect.Value, error) {
+func (m *Masker) maskSlice(s *maskState, rv reflect.Value, mp reflect.Value, tag ...string) (reflect.Value, error) {
 	var rv2 reflect.Value
 
 	if rt := rv.Type(); rt.Kind() == reflect.Array {
 		rv2 = reflect.New(rt).Elem()
+	} else if rv.Len() != 0 {
+		// a slice may hold itself through an interface
+		key := visitKey{ptr: rv.Pointer(), len: rv.Len(), typ: rt, tag: strings.Join(tag, ",")}
+		if copied, visited := s.visited[key]; visited {
+			rv2 = copied
+			if mp.IsValid() {
+				mp.Set(rv2)
+				return mp, nil
+			}
+			return rv2, nil
+		}
+		rv2 = reflect.MakeSlice(rt, rv.Len(), rv.Len())
+		s.visited[key] = rv2
 	} else {
-		rv2 = reflect.MakeSlice(rv.Type(), rv.Len(), rv.Len())
+		rv2 = reflect.MakeSlice(rt, 0, 0)
 	}
 	for i := 0; i < rv.Len(); i++ {
 		value := rv.Index(i)
-		switch rv.Type().Elem().Kind() {
-		case reflect.String:
-			rvf, err := m.String(value.String(), tag...)
-			if err != nil {
-				return reflect.Value{}, err
-			}
-			rv2.Index(i).SetString(rvf)
-		case reflect.Int:
-			rvf, err := m.Int(int(value.Int()), tag...)
-			if err != nil {
-				return reflect.Value{}, err
-			}
-			rv2.Index(i).SetInt(int64(rvf))
-		case reflect.Float64:
-			rvf, err := m.Float64(value.Float(), tag...)
-			if err != nil {
-				return reflect.Value{}, err
-			}
-			rv2.Index(i).SetFloat(rvf)
-		case reflect.Uint:
-			rvf, err := m.Uint(uint(value.Uint()), tag...)
-			if err != nil {
+		s.push(fmt.Sprintf("[%d]", i))
+		rvf, err := m.mask(s, value, rv2.Index(i), tag...)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		rv2.Index(i).Set(rvf)
+		s.pop()
+	}
+
+	if mp.IsValid() {
+		mp.Set(rv2)
+		return mp, nil
+	}
+
+	return rv2, nil
+}
+
+func (m *Masker) maskMap(s *maskState, rv reflect.Value, mp reflect.Value, tag ...string) (reflect.Value, error) {
+	if rv.IsNil() {
+		return reflect.Zero(rv.Type()), nil
+	}
+
+	key := visitKey{ptr: rv.Pointer(), typ: rv.Type(), tag: strings.Join(tag, ",")}
+	if rv2, visited := s.visited[key]; visited {
+		if mp.IsValid() {
+			mp.Set(rv2)
+			return mp, nil
+		}
+		return rv2, nil
+	}
+
+	rt := rv.Type()
+	rv2 := reflect.MakeMapWithSize(rt, rv.Len())
+	s.visited[key] = rv2
+	masksKeys := s.cfg.masksKeys(rt, tag)
+	iter := rv.MapRange()
+	for iter.Next() {
+		// keys may be sensitive themselves, they are kept out of paths
+		s.push("[*]")
+		rvf, err := m.maskEntry(s, iter.Key(), iter.Value(), reflect.New(rt.Elem()).Elem(), tag...)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		k := iter.Key()
+		if masksKeys {
+			if k, err = m.mask(s, k, reflect.Value{}); err != nil {
 				return reflect.Value{}, err
 			}
-			rv2.Index(i).SetUint(uint64(rvf))
-		default:
-			rvf, err := m.mask(value, rv2.Index(i), tag...)
-			if err != nil {
-				return reflect.Value{}, err
+			if rv2.MapIndex(k).IsValid() {
+				s.pop()
+				rv3, err := m.fail(s, rv, mp, ErrKeyCollision, tag)
+				if err == nil {
+					s.visited[key] = rv3
+				}
+				return rv3, err
 			}
-			rv2.Index(i).Set(rvf)
 		}
+		rv2.SetMapIndex(k, rvf)
+		s.pop()
 	}
 
 	if mp.IsValid() {
@@ -301,7 +1380,62 @@ func (m *Masker) maskSlice(rv reflect.Value, mp reflect.Value, tag ...string) (r
 	return rv2, nil
 }
 
-func (m *Masker) maskFloat(rv reflect.Value, mp reflect.Value, tag ...string) (reflect.Value, error) {
+// maskEntry masks the value of a map entry, an untagged value whose key is
+// a sensitive name is masked by the name heuristics
+func (m *Masker) maskEntry(s *maskState, key reflect.Value, rv reflect.Value, mp reflect.Value, tag ...string) (reflect.Value, error) {
+	if len(tag) != 0 && len(tag[0]) != 0 {
+		return m.mask(s, rv, mp, tag...)
+	}
+
+	hit, r, err := m.keyRule(s, key, rv)
+	switch {
+	case !hit:
+		return m.mask(s, rv, mp, tag...)
+	case err != nil:
+		return m.fail(s, rv, mp, err, []string{s.cfg.nameTag})
+	case r == nil:
+		return reflect.Zero(rv.Type()), nil
+	default:
+		return m.maskRule(s, rv, mp, r)
+	}
+}
+
+// maskBytes masks []byte, json.RawMessage and other byte slices as a whole
+// with the string masks instead of masking them byte by byte.
+func (c *config) maskBytes(rv reflect.Value, mp reflect.Value, tag ...string) (reflect.Value, error) {
+	var bp reflect.Value
+	if len(tag) == 0 || len(tag[0]) == 0 {
+		bp = reflect.MakeSlice(rv.Type(), rv.Len(), rv.Len())
+		reflect.Copy(bp, rv)
+	} else if maskFunc, exist := c.maskStringFuncMap[tag[0]]; exist {
+		s, err := maskFunc(string(rv.Bytes()), tag[1:]...)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		bp = reflect.ValueOf([]byte(s)).Convert(rv.Type())
+	} else if ok, v, err := c.Any(rv.Interface(), tag...); ok {
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if bp, err = anyResult(v, rv.Type(), tag[0]); err != nil {
+			return reflect.Value{}, err
+		}
+	} else if err := c.missing(tag[0], rv.Type().String()); err != nil {
+		return reflect.Value{}, err
+	} else {
+		bp = reflect.MakeSlice(rv.Type(), rv.Len(), rv.Len())
+		reflect.Copy(bp, rv)
+	}
+
+	if mp.IsValid() {
+		mp.Set(bp)
+		return mp, nil
+	}
+
+	return bp, nil
+}
+
+func (c *config) maskFloat(rv reflect.Value, mp reflect.Value, tag ...string) (reflect.Value, error) {
 	if len(tag) == 0 {
 		if mp.IsValid() {
 			mp.Set(rv)
@@ -310,7 +1444,17 @@ func (m *Masker) maskFloat(rv reflect.Value, mp reflect.Value, tag ...string) (r
 		return rv, nil
 	}
 
-	fp, err := m.Float64(rv.Float(), tag...)
+	var (
+		fp  float64
+		err error
+	)
+	if rv.Type().Kind() == reflect.Float32 {
+		var v float32
+		v, err = c.Float32(float32(rv.Float()), tag...)
+		fp = float64(v)
+	} else {
+		fp, err = c.Float64(rv.Float(), tag...)
+	}
 	if err != nil {
 		return reflect.Value{}, err
 	}
@@ -319,14 +1463,10 @@ func (m *Masker) maskFloat(rv reflect.Value, mp reflect.Value, tag ...string) (r
 		return mp, nil
 	}
 
-	if rv.Type().Kind() != reflect.Float64 {
-		return reflect.ValueOf(&fp).Elem().Convert(rv.Type()), nil
-	}
-
-	return reflect.ValueOf(&fp).Elem(), nil
+	return reflect.ValueOf(&fp).Elem().Convert(rv.Type()), nil
 }
 
-func (m *Masker) maskString(rv, mp reflect.Value, tag ...string) (reflect.Value, error) {
+func (c *config) maskString(rv, mp reflect.Value, tag ...string) (reflect.Value, error) {
 	if len(tag) == 0 {
 		if mp.IsValid() {
 			mp.Set(rv)
@@ -335,7 +1475,7 @@ func (m *Masker) maskString(rv, mp reflect.Value, tag ...string) (reflect.Value,
 		return rv, nil
 	}
 
-	sp, err := m.String(rv.String(), tag...)
+	sp, err := c.String(rv.String(), tag...)
 	if err != nil {
 		return reflect.Value{}, err
 	}
@@ -351,7 +1491,7 @@ func valueOfString(s string) reflect.Value {
 	return reflect.ValueOf(&s).Elem()
 }
 
-func (m *Masker) maskInt(rv reflect.Value, mp reflect.Value, tag ...string) (reflect.Value, error) {
+func (c *config) maskInt(rv reflect.Value, mp reflect.Value, tag ...string) (reflect.Value, error) {
 	if len(tag) == 0 {
 		if mp.IsValid() {
 			mp.Set(rv)
@@ -360,23 +1500,86 @@ func (m *Masker) maskInt(rv reflect.Value, mp reflect.Value, tag ...string) (ref
 		return rv, nil
 	}
 
-	ip, err := m.Int(int(rv.Int()), tag...)
+	var (
+		ip  int64
+		err error
+	)
+	switch rv.Type().Kind() {
+	case reflect.Int8:
+		var v int8
+		v, err = c.Int8(int8(rv.Int()), tag...)
+		ip = int64(v)
+	case reflect.Int16:
+		var v int16
+		v, err = c.Int16(int16(rv.Int()), tag...)
+		ip = int64(v)
+	case reflect.Int32:
+		var v int32
+		v, err = c.Int32(int32(rv.Int()), tag...)
+		ip = int64(v)
+	case reflect.Int64:
+		ip, err = c.Int64(rv.Int(), tag...)
+	default:
+		var v int
+		v, err = c.Int(int(rv.Int()), tag...)
+		ip = int64(v)
+	}
 	if err != nil {
 		return reflect.Value{}, err
 	}
 	if mp.IsValid() {
-		mp.SetInt(int64(ip))
+		mp.SetInt(ip)
 		return mp, nil
 	}
 
-	if rv.Type().Kind() != reflect.Int {
-		return reflect.ValueOf(&ip).Elem().Convert(rv.Type()), nil
+	return reflect.ValueOf(&ip).Elem().Convert(rv.Type()), nil
+}
+
+func (c *config) maskUint(rv reflect.Value, mp reflect.Value, tag ...string) (reflect.Value, error) {
+	if len(tag) == 0 {
+		if mp.IsValid() {
+			mp.Set(rv)
+			return mp, nil
+		}
+		return rv, nil
+	}
+
+	var (
+		up  uint64
+		err error
+	)
+	switch rv.Type().Kind() {
+	case reflect.Uint8:
+		var v uint8
+		v, err = c.Uint8(uint8(rv.Uint()), tag...)
+		up = uint64(v)
+	case reflect.Uint16:
+		var v uint16
+		v, err = c.Uint16(uint16(rv.Uint()), tag...)
+		up = uint64(v)
+	case reflect.Uint32:
+		var v uint32
+		v, err = c.Uint32(uint32(rv.Uint()), tag...)
+		up = uint64(v)
+	case reflect.Uint64:
+		up, err = c.Uint64(rv.Uint(), tag...)
+	default:
+		var v uint
+		v, err = c.Uint(uint(rv.Uint()), tag...)
+		up = uint64(v)
+	}
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	if mp.IsValid() {
+		mp.SetUint(up)
+		return mp, nil
 	}
 
-	return reflect.ValueOf(&ip).Elem(), nil
+	return reflect.ValueOf(&up).Elem().Convert(rv.Type()), nil
 }
 
-func (m *Masker) maskUint(rv reflect.Value, mp reflect.Value, tag ...string) (reflect.Value, error) {
+func (c *config) maskBool(rv reflect.Value, mp reflect.Value, tag ...string) (reflect.Value, error) {
 	if len(tag) == 0 {
 		if mp.IsValid() {
 			mp.Set(rv)
@@ -385,18 +1588,45 @@ func (m *Masker) maskUint(rv reflect.Value, mp reflect.Value, tag ...string) (re
 		return rv, nil
 	}
 
-	ip, err := m.Uint(uint(rv.Uint()), tag...)
+	bp, err := c.Bool(rv.Bool(), tag...)
 	if err != nil {
 		return reflect.Value{}, err
 	}
 	if mp.IsValid() {
-		mp.SetUint(uint64(ip))
+		mp.SetBool(bp)
 		return mp, nil
 	}
 
-	if rv.Type().Kind() != reflect.Uint {
-		return reflect.ValueOf(&ip).Elem().Convert(rv.Type()), nil
+	return reflect.ValueOf(&bp).Elem().Convert(rv.Type()), nil
+}
+
+func (c *config) maskComplex(rv reflect.Value, mp reflect.Value, tag ...string) (reflect.Value, error) {
+	if len(tag) == 0 {
+		if mp.IsValid() {
+			mp.Set(rv)
+			return mp, nil
+		}
+		return rv, nil
+	}
+
+	var (
+		cp  complex128
+		err error
+	)
+	if rv.Type().Kind() == reflect.Complex64 {
+		var v complex64
+		v, err = c.Complex64(complex64(rv.Complex()), tag...)
+		cp = complex128(v)
+	} else {
+		cp, err = c.Complex128(rv.Complex(), tag...)
+	}
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	if mp.IsValid() {
+		mp.SetComplex(cp)
+		return mp, nil
 	}
 
-	return reflect.ValueOf(&ip).Elem(), nil
+	return reflect.ValueOf(&cp).Elem().Convert(rv.Type()), nil
 }
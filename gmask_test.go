@@ -1,9 +1,16 @@
 package gmask
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/stretchr/testify/assert"
+	"math"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 type testStruct struct {
@@ -63,7 +70,7 @@ func TestMasker_Mask(t *testing.T) {
 		FloatZero: 57128, // This field will be 0
 		AnyZero:   "This field will be empty",
 	}
-	demoMasked, err := New().RegMaskAnyFunc(MaskTypeZero, MaskZero).Mask(demo)
+	demoMasked, err := New().RegMaskAnyFunc(MaskTypeZero, MaskZero).SetStrict(false).Mask(demo)
 	assert.NoError(t, err)
 	masked := demoMasked.(testStruct)
 	assert.Zero(t, masked.StrZero)
@@ -157,3 +164,414 @@ func TestMasker_Any(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, (map[string]string)(nil), masked)
 }
+
+func TestMasker_ExactKind(t *testing.T) {
+	type sized struct {
+		Int8    int8       `mask:"rand"`
+		Int16   int16      `mask:"rand,100,-100"`
+		Int64   int64      `mask:"zero"`
+		Uint8   uint8      `mask:"rand"`
+		Uint32  uint32     `mask:"rand,10"`
+		Float32 float32    `mask:"rand,10,5"`
+		Bool    bool       `mask:"zero"`
+		Complex complex128 `mask:"zero"`
+		Large   int64
+	}
+
+	for i := 0; i < 100; i++ {
+		masked, err := Mask(sized{
+			Int8:    -1,
+			Int16:   -1000,
+			Int64:   math.MaxInt64,
+			Uint8:   1,
+			Uint32:  math.MaxUint32,
+			Float32: 1,
+			Bool:    true,
+			Complex: 1 + 1i,
+			Large:   math.MaxInt64,
+		})
+		assert.NoError(t, err)
+		assert.True(t, 0 <= masked.Int8)
+		assert.True(t, -100 <= masked.Int16 && masked.Int16 < 100)
+		assert.Zero(t, masked.Int64)
+		assert.True(t, masked.Uint32 < 10)
+		assert.True(t, 5 <= masked.Float32 && masked.Float32 < 10)
+		assert.False(t, masked.Bool)
+		assert.Zero(t, masked.Complex)
+		assert.Equal(t, int64(math.MaxInt64), masked.Large)
+	}
+
+	// masks registered for the wide kinds apply to sized kinds when the result fits
+	m := New().RegMaskIntFunc("const", func(_ int, arg ...string) (int, error) {
+		return 300, nil
+	})
+	i16, err := m.Int16(1, "const")
+	assert.NoError(t, err)
+	assert.Equal(t, int16(300), i16)
+	_, err = m.Int8(1, "const")
+	assert.Error(t, err)
+}
+
+func TestMasker_Bytes(t *testing.T) {
+	type secret []byte
+	type bytesStruct struct {
+		Password []byte          `mask:"char"`
+		Named    secret          `mask:"char,-1"`
+		Raw      json.RawMessage `mask:"json,password"`
+		Zero     []byte          `mask:"zero"`
+		Plain    []byte
+	}
+
+	demo := bytesStruct{
+		Password: []byte("password"),
+		Named:    secret("abc"),
+		Raw:      json.RawMessage(`{"name":"foo","password":"bar"}`),
+		Zero:     []byte("zero"),
+		Plain:    []byte("plain"),
+	}
+	masked, err := Mask(demo)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("********"), masked.Password)
+	assert.Equal(t, secret("***"), masked.Named)
+	assert.JSONEq(t, `{"name":"foo","password":""}`, string(masked.Raw))
+	assert.Nil(t, masked.Zero)
+	assert.Equal(t, []byte("plain"), masked.Plain)
+
+	// the untagged copy must not share memory with the original
+	masked.Plain[0] = 'P'
+	assert.Equal(t, []byte("plain"), demo.Plain)
+}
+
+func TestMasker_Container(t *testing.T) {
+	type inner struct {
+		Name string
+	}
+	type containerStruct struct {
+		Struct     inner             `mask:"zero"`
+		Slice      []string          `mask:"zero"`
+		SliceOmit  []string          `mask:"omit"`
+		SliceLen   []string          `mask:"len"`
+		MapLen     map[string]string `mask:"len"`
+		StructLen  inner             `mask:"len"`
+		SliceEach  []string          `mask:"each:zero"`
+		SliceChar  []string          `mask:"char,3"`
+		Map        map[string]string `mask:"zero"`
+		MapEach    map[string]string `mask:"each:char,3"`
+		BytesEach  [][]byte          `mask:"each:zero"`
+		NestedEach [][]string        `mask:"char,1"`
+	}
+
+	demo := containerStruct{
+		Struct:     inner{Name: "foo"},
+		Slice:      []string{"foo", "bar"},
+		SliceOmit:  []string{"foo", "bar"},
+		SliceLen:   []string{"foo", "bar"},
+		MapLen:     map[string]string{"foo": "bar"},
+		StructLen:  inner{Name: "foo"},
+		SliceEach:  []string{"foo", "bar"},
+		SliceChar:  []string{"foo", "bar"},
+		Map:        map[string]string{"foo": "bar"},
+		MapEach:    map[string]string{"foo": "bar"},
+		BytesEach:  [][]byte{[]byte("foo")},
+		NestedEach: [][]string{{"foo"}, {"bar"}},
+	}
+	masked, err := Mask(demo)
+	assert.NoError(t, err)
+	assert.Zero(t, masked.Struct)
+	assert.Nil(t, masked.Slice)
+	assert.Nil(t, masked.SliceOmit)
+	assert.Equal(t, []string{"", ""}, masked.SliceLen)
+	assert.Equal(t, map[string]string{"foo": ""}, masked.MapLen)
+	assert.Zero(t, masked.StructLen)
+	assert.Equal(t, []string{"", ""}, masked.SliceEach)
+	assert.Equal(t, []string{"***", "***"}, masked.SliceChar)
+	assert.Nil(t, masked.Map)
+	assert.Equal(t, map[string]string{"foo": "***"}, masked.MapEach)
+	assert.Equal(t, [][]byte{nil}, masked.BytesEach)
+	assert.Equal(t, [][]string{{"*"}, {"*"}}, masked.NestedEach)
+
+	// the original map must not be touched
+	assert.Equal(t, map[string]string{"foo": "bar"}, demo.MapEach)
+	assert.Equal(t, map[string]string{"foo": "bar"}, demo.MapLen)
+}
+
+func TestMasker_SetKeepUnexported(t *testing.T) {
+	type privateStruct struct {
+		Secret  string `mask:"zero"`
+		Created time.Time
+		private string
+	}
+
+	now := time.Now()
+	demo := privateStruct{Secret: "secret", Created: now, private: "private"}
+
+	masked, err := New().RegMaskAnyFunc(MaskTypeZero, MaskZero).Mask(demo)
+	assert.NoError(t, err)
+	assert.Zero(t, masked.(privateStruct).Created)
+	assert.Zero(t, masked.(privateStruct).private)
+
+	masked, err = New().RegMaskAnyFunc(MaskTypeZero, MaskZero).SetKeepUnexported(true).Mask(demo)
+	assert.NoError(t, err)
+	assert.Zero(t, masked.(privateStruct).Secret)
+	assert.True(t, now.Equal(masked.(privateStruct).Created))
+	assert.Equal(t, "private", masked.(privateStruct).private)
+	assert.Equal(t, "secret", demo.Secret)
+}
+
+func TestMasker_Cycle(t *testing.T) {
+	type node struct {
+		Secret string `mask:"zero"`
+		Next   *node
+		Alias  *node
+	}
+
+	a := &node{Secret: "a"}
+	b := &node{Secret: "b", Next: a}
+	a.Next = b
+	a.Alias = b
+
+	masked, err := Mask(a)
+	assert.NoError(t, err)
+	assert.Zero(t, masked.Secret)
+	assert.Zero(t, masked.Next.Secret)
+	assert.Same(t, masked, masked.Next.Next)
+	assert.Same(t, masked.Next, masked.Alias)
+	assert.NotSame(t, a, masked)
+	assert.Equal(t, "a", a.Secret)
+
+	m := map[string]any{"secret": "foo"}
+	m["self"] = m
+	maskedMap, err := New().Mask(m)
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", maskedMap.(map[string]any)["secret"])
+
+	// a slice holding itself
+	slice := []any{nil, "foo"}
+	slice[0] = slice
+	maskedSlice, err := New().RegMaskAnyFunc(MaskTypeZero, MaskZero).Mask(slice)
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", maskedSlice.([]any)[1])
+	self := maskedSlice.([]any)[0].([]any)
+	assert.Same(t, &maskedSlice.([]any)[0], &self[0])
+	assert.NotSame(t, &slice[0], &self[0])
+
+	err = New().MaskInPlace(&slice)
+	assert.NoError(t, err)
+}
+
+func TestMasker_SetMaxDepth(t *testing.T) {
+	type node struct {
+		Next *node
+	}
+
+	deep := &node{Next: &node{Next: &node{}}}
+	_, err := New().SetMaxDepth(4).Mask(deep)
+	assert.Error(t, err)
+
+	_, err = New().SetMaxDepth(6).Mask(deep)
+	assert.NoError(t, err)
+}
+
+func TestMasker_SetFallback(t *testing.T) {
+	type record struct {
+		Name   string
+		Hash   string `mask:"hash,sha512"`
+		Char   string `mask:"char,x"`
+		Amount int64  `mask:"rand,-1"`
+		Cards  []string
+	}
+	demo := record{Name: "foo", Hash: "secret", Char: "secret", Amount: 100}
+
+	m := New().
+		RegMaskStringFunc(MaskTypeChar, MaskCharString()).
+		RegMaskStringFunc(MaskTypeHash, MaskHashString).
+		RegMaskInt64Func(MaskTypeRandom, MaskRandInt64)
+
+	_, err := m.Mask(demo)
+	assert.Error(t, err)
+
+	masked, err := m.SetFallback(MaskRedacted).Mask(demo)
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+	assert.Len(t, err.(interface{ Unwrap() []error }).Unwrap(), 3)
+	var maskErr *MaskError
+	assert.True(t, errors.As(err, &maskErr))
+	assert.Equal(t, "record.Hash", maskErr.Path)
+	assert.Equal(t, record{Name: "foo", Hash: Redacted, Char: Redacted}, masked)
+
+	masked, err = m.SetFallback(MaskFixed("-")).Mask(demo)
+	assert.Error(t, err)
+	assert.Equal(t, record{Name: "foo", Hash: "-", Char: "-"}, masked)
+
+	// the fallback gets the arguments of the failed mask, not its name
+	var args [][]string
+	_, err = m.SetFallback(func(value any, arg ...string) (any, error) {
+		args = append(args, arg)
+		return MaskZero(value)
+	}).Mask(demo)
+	assert.Error(t, err)
+	assert.Equal(t, [][]string{{"sha512"}, {"x"}, {"-1"}}, args)
+
+	err = m.SetFallback(MaskZero).MaskInPlace(&demo)
+	assert.Error(t, err)
+	assert.Equal(t, record{Name: "foo"}, demo)
+}
+
+func TestMasker_SetStrict(t *testing.T) {
+	m := New().
+		RegMaskAnyFunc(MaskTypeZero, MaskZero).
+		RegMaskStringFunc(MaskTypeHash, MaskHashString)
+
+	_, err := m.String("secret", "hsah")
+	assert.ErrorIs(t, err, ErrUnknownStrategy)
+
+	_, err = m.Int(57128, MaskTypeHash)
+	assert.ErrorIs(t, err, ErrUnsupportedKind)
+
+	_, err = m.Mask(struct {
+		Inner struct{ Name string } `mask:"hash"`
+	}{Inner: struct{ Name string }{Name: "foo"}})
+	assert.ErrorIs(t, err, ErrUnsupportedKind)
+
+	_, err = m.Mask(struct {
+		Secrets []string `mask:"each:hsah"`
+	}{Secrets: []string{"secret"}})
+	assert.ErrorIs(t, err, ErrUnknownStrategy)
+
+	// registered masks keep working
+	masked, err := m.Mask(struct {
+		Secret string `mask:"zero"`
+	}{Secret: "secret"})
+	assert.NoError(t, err)
+	assert.Zero(t, masked)
+
+	// lenient mode leaves the value as it was
+	s, err := m.SetStrict(false).String("secret", "hsah")
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", s)
+}
+
+func TestMasker_Clone(t *testing.T) {
+	base := New().RegMaskAnyFunc(MaskTypeZero, MaskZero)
+	clone := base.Clone().
+		RegMaskStringFunc(MaskTypeChar, MaskCharString()).
+		SetStrict(false)
+
+	_, err := base.String("secret", MaskTypeChar)
+	assert.ErrorIs(t, err, ErrUnknownStrategy)
+	s, err := clone.String("secret", MaskTypeChar)
+	assert.NoError(t, err)
+	assert.Equal(t, "********", s)
+
+	base.RegMaskStringFunc(MaskTypeHash, MaskHashString)
+	s, err = clone.String("secret", MaskTypeHash)
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", s)
+
+	v, err := clone.Mask(struct {
+		Secret string `mask:"zero"`
+	}{Secret: "secret"})
+	assert.NoError(t, err)
+	assert.Zero(t, v)
+}
+
+func TestMasker_Concurrent(t *testing.T) {
+	type user struct {
+		Name  string `mask:"char,3"`
+		Email string `mask:"fresh ?? zero"`
+	}
+
+	m := New().
+		RegMaskAnyFunc(MaskTypeZero, MaskZero).
+		RegMaskStringFunc(MaskTypeChar, MaskCharString())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				masked, err := m.Mask(user{Name: "foo", Email: "foo@bar.com"})
+				assert.NoError(t, err)
+				assert.Equal(t, "***", masked.(user).Name)
+			}
+		}()
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				m.RegMaskStringFunc(fmt.Sprintf("mask%d-%d", i, j), MaskHashString).
+					SetMaxDepth(0).
+					RegMaskArgNames(MaskTypeChar, "len", "with")
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	m.RegMaskStringFunc("fresh", MaskHashString)
+	masked, err := m.Mask(user{Name: "foo", Email: "foo@bar.com"})
+	assert.NoError(t, err)
+	assert.Len(t, masked.(user).Email, 64)
+}
+
+func TestMasker_ConfigSnapshot(t *testing.T) {
+	type pair struct {
+		A string `mask:"register"`
+		B string `mask:"late"`
+	}
+
+	// a mask registered while masking is only used by later calls
+	m := New().SetStrict(false)
+	m.RegMaskFieldFunc("register", func(fc FieldContext, value any, _ ...string) (any, error) {
+		fc.Masker.RegMaskStringFunc("late", func(string, ...string) (string, error) {
+			return "LATE", nil
+		})
+		return value, nil
+	})
+	masked, err := m.Mask(pair{A: "a", B: "b"})
+	assert.NoError(t, err)
+	assert.Equal(t, pair{A: "a", B: "b"}, masked)
+
+	masked, err = m.Mask(pair{A: "a", B: "b"})
+	assert.NoError(t, err)
+	assert.Equal(t, pair{A: "a", B: "LATE"}, masked)
+
+	demo := pair{A: "a", B: "b"}
+	assert.NoError(t, m.Clone().MaskInPlace(&demo))
+	assert.Equal(t, pair{A: "a", B: "LATE"}, demo)
+}
+
+func TestSetDefault(t *testing.T) {
+	type user struct {
+		Email string `mask:"email"`
+	}
+
+	_, err := Mask(user{Email: "foo@bar.com"})
+	assert.ErrorIs(t, err, ErrUnknownStrategy)
+
+	previous := Default()
+	defer SetDefault(previous)
+	SetDefault(NewWithDefaults().RegMaskStringFunc("email", func(value string, _ ...string) (string, error) {
+		return "***@" + value[strings.IndexByte(value, '@')+1:], nil
+	}))
+
+	masked, err := Mask(user{Email: "foo@bar.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, user{Email: "***@bar.com"}, masked)
+	s, err := String("foo", MaskTypeChar, "3")
+	assert.NoError(t, err)
+	assert.Equal(t, "***", s)
+
+	// with a fallback the masked value comes along with the errors
+	SetDefault(New(WithDefaults(), WithFallback(MaskRedacted)))
+	type token struct {
+		S string `mask:"hash,xx"`
+	}
+	masked2, err := Mask(token{S: "secret"})
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+	assert.Equal(t, token{S: Redacted}, masked2)
+	masked2, err = MaskContext(context.Background(), token{S: "secret"})
+	assert.Error(t, err)
+	assert.Equal(t, token{S: Redacted}, masked2)
+
+	assert.Panics(t, func() { SetDefault(nil) })
+	assert.NotSame(t, NewWithDefaults(), NewWithDefaults())
+}
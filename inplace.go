@@ -0,0 +1,323 @@
+package gmask
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// MaskInPlace masks the value ptr points to directly instead of returning
+// a masked copy, fields without mask rules are left untouched.
+// ptr must be a non-nil pointer.
+func (m *Masker) MaskInPlace(ptr any) error {
+	rv := reflect.ValueOf(ptr)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("%w: MaskInPlace needs a non-nil pointer, got %T", ErrInvalidArgument, ptr)
+	}
+
+	s := m.newState(context.Background(), rv.Type())
+	if err := m.maskInPlace(s, rv); err != nil {
+		return err
+	}
+
+	return errors.Join(s.errs...)
+}
+
+func (m *Masker) maskInPlace(s *maskState, rv reflect.Value, tag ...string) error {
+	err := m.maskStageInPlace(s, rv, tag...)
+	if err == nil {
+		return nil
+	}
+	if !rv.CanSet() {
+		return err
+	}
+
+	_, err = m.fail(s, rv, rv, err, tag)
+	return err
+}
+
+// maskStageInPlace masks rv with a single mask, it never falls back
+func (m *Masker) maskStageInPlace(s *maskState, rv reflect.Value, tag ...string) error {
+	if err := m.enter(s); err != nil {
+		return s.wrap(err, tag)
+	}
+	defer s.leave()
+
+	if err := m.maskValueInPlace(s, rv, tag...); err != nil {
+		return s.wrap(err, tag)
+	}
+
+	return nil
+}
+
+// maskRuleInPlace masks rv with the pipelines of r in order until one
+// succeeds, a failed pipeline is undone before trying the next one
+func (m *Masker) maskRuleInPlace(s *maskState, rv reflect.Value, r rule) error {
+	if len(r) == 1 && len(r[0]) == 1 {
+		return m.maskInPlace(s, rv, r[0][0]...)
+	}
+
+	original := reflect.New(rv.Type()).Elem()
+	original.Set(rv)
+
+	// a failed alternative may leave the segments it pushed on the path
+	depth := len(s.path)
+	var errs []error
+	for _, p := range r {
+		err := m.maskPipelineInPlace(s, rv, p)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+		rv.Set(original)
+		s.path = s.path[:depth]
+	}
+
+	_, err := m.fail(s, rv, rv, errors.Join(errs...), nil)
+	return err
+}
+
+func (m *Masker) maskPipelineInPlace(s *maskState, rv reflect.Value, p pipeline) error {
+	for _, st := range p {
+		if err := m.maskStageInPlace(s, rv, st...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *Masker) maskValueInPlace(s *maskState, rv reflect.Value, tag ...string) error {
+	tag, zero := s.cfg.denyTag(rv.Type(), tag)
+	if zero {
+		rv.Set(reflect.Zero(rv.Type()))
+		return nil
+	}
+
+	if ok, _, err := m.maskField(s, rv, rv, tag...); ok {
+		return err
+	}
+
+	switch rv.Type().Kind() {
+	case reflect.Struct, reflect.Array, reflect.Slice, reflect.Map:
+		if ok, _, err := s.cfg.maskWhole(rv, rv, tag...); ok {
+			return err
+		}
+	}
+
+	switch rv.Type().Kind() {
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return nil
+		}
+		key := visitKey{ptr: rv.Pointer(), typ: rv.Type(), tag: strings.Join(tag, ",")}
+		if _, visited := s.visited[key]; visited {
+			return nil
+		}
+		s.visited[key] = rv
+		return m.maskInPlace(s, rv.Elem(), tag...)
+	case reflect.Interface:
+		return m.maskInterfaceInPlace(s, rv, tag...)
+	case reflect.Struct:
+		if len(tag) != 0 && len(tag[0]) != 0 {
+			if err := s.cfg.missing(tag[0], rv.Type().String()); err != nil {
+				return err
+			}
+		}
+		rt := rv.Type()
+		parent, outer := s.parent, s.field
+		defer func() { s.parent, s.field = parent, outer }()
+		defer s.enterPolicy(rt)()
+		// conditions and field masks read the struct as it was before any
+		// of its fields got masked, as Mask does
+		original := rv
+		if s.cfg.readsParent(rt) {
+			original = reflect.New(rt).Elem()
+			original.Set(rv)
+		}
+		for i := 0; i < rt.NumField(); i++ {
+			field := rt.Field(i)
+			// skip private field
+			if field.PkgPath != "" {
+				continue
+			}
+
+			// a scalar field without rule is left as it is
+			if len(s.policies) == 0 && !s.cfg.needsMask(field.Type, nil) && !hasCond(field) && len(s.cfg.structTag(field)) == 0 {
+				continue
+			}
+
+			s.push(field.Name)
+			s.parent, s.field = original, field
+			r, err := m.fieldRule(s, field)
+			switch {
+			case err != nil:
+				_, err = m.fail(s, rv.Field(i), rv.Field(i), err, []string{m.fieldTag(s, field)})
+			case r == nil:
+				rv.Field(i).Set(reflect.Zero(field.Type))
+			default:
+				err = m.maskRuleInPlace(s, rv.Field(i), r)
+			}
+			if err != nil {
+				return err
+			}
+			s.pop()
+		}
+		return nil
+	case reflect.Slice:
+		if rv.IsNil() {
+			return nil
+		}
+		if rv.Type().Elem().Kind() == reflect.Uint8 && !isEachTag(tag) {
+			if len(tag) == 0 || len(tag[0]) == 0 {
+				return nil
+			}
+			_, err := s.cfg.maskBytes(rv, rv, tag...)
+			return err
+		}
+		return m.maskElemInPlace(s, rv, eachTag(tag)...)
+	case reflect.Array:
+		return m.maskElemInPlace(s, rv, eachTag(tag)...)
+	case reflect.Map:
+		return m.maskMapInPlace(s, rv, eachTag(tag)...)
+	default:
+		if len(tag) == 0 || len(tag[0]) == 0 {
+			return nil
+		}
+		// the value is entered already, do not count it twice
+		_, err := s.cfg.maskScalar(rv, rv, tag...)
+		return err
+	}
+}
+
+func (m *Masker) maskInterfaceInPlace(s *maskState, rv reflect.Value, tag ...string) error {
+	if rv.IsNil() {
+		return nil
+	}
+
+	// the value held by an interface is not addressable, mutate through it
+	// when it is a pointer, otherwise replace it with a masked copy
+	elem := rv.Elem()
+	if elem.Kind() == reflect.Ptr {
+		return m.maskInPlace(s, elem, tag...)
+	}
+
+	mp := reflect.New(elem.Type()).Elem()
+	rv2, err := m.mask(s, elem, mp, tag...)
+	if err != nil {
+		return err
+	}
+	rv.Set(rv2)
+
+	return nil
+}
+
+func (m *Masker) maskElemInPlace(s *maskState, rv reflect.Value, tag ...string) error {
+	if !s.cfg.needsMask(rv.Type().Elem(), tag) {
+		return nil
+	}
+	if rv.Kind() == reflect.Slice && rv.Len() != 0 {
+		key := visitKey{ptr: rv.Pointer(), len: rv.Len(), typ: rv.Type(), tag: strings.Join(tag, ",")}
+		if _, visited := s.visited[key]; visited {
+			return nil
+		}
+		s.visited[key] = rv
+	}
+
+	for i := 0; i < rv.Len(); i++ {
+		s.push(fmt.Sprintf("[%d]", i))
+		if err := m.maskInPlace(s, rv.Index(i), tag...); err != nil {
+			return err
+		}
+		s.pop()
+	}
+
+	return nil
+}
+
+func (m *Masker) maskMapInPlace(s *maskState, rv reflect.Value, tag ...string) error {
+	byName := len(s.cfg.nameTag) != 0 && rv.Type().Key().Kind() == reflect.String
+	if rv.IsNil() || !byName && !s.cfg.needsMask(rv.Type().Elem(), tag) {
+		return nil
+	}
+
+	key := visitKey{ptr: rv.Pointer(), typ: rv.Type(), tag: strings.Join(tag, ",")}
+	if _, visited := s.visited[key]; visited {
+		return nil
+	}
+	s.visited[key] = rv
+
+	// map values are not addressable, so they are masked into a copy
+	// which then replaces the original value
+	elemType := rv.Type().Elem()
+	masksKeys := s.cfg.masksKeys(rv.Type(), tag)
+	var masked reflect.Value
+	if masksKeys {
+		masked = reflect.MakeMapWithSize(rv.Type(), rv.Len())
+	}
+	iter := rv.MapRange()
+	for iter.Next() {
+		// keys may be sensitive themselves, they are kept out of paths
+		s.push("[*]")
+		mp := reflect.New(elemType).Elem()
+		rvf, err := m.maskEntry(s, iter.Key(), iter.Value(), mp, tag...)
+		if err != nil {
+			return err
+		}
+		if masksKeys {
+			k, err := m.mask(s, iter.Key(), reflect.Value{})
+			if err != nil {
+				return err
+			}
+			if masked.MapIndex(k).IsValid() {
+				s.pop()
+				rv2, err := m.fail(s, rv, reflect.Value{}, ErrKeyCollision, tag)
+				if err != nil {
+					return err
+				}
+				setEntries(rv, rv2)
+				return nil
+			}
+			masked.SetMapIndex(k, rvf)
+		} else {
+			rv.SetMapIndex(iter.Key(), rvf)
+		}
+		s.pop()
+	}
+
+	// masked keys replace the original ones once the walk of the map is done
+	if masksKeys {
+		setEntries(rv, masked)
+	}
+
+	return nil
+}
+
+// setEntries replaces the entries of the map rv with those of rv2, the map
+// may not be settable as a whole
+func setEntries(rv reflect.Value, rv2 reflect.Value) {
+	for _, k := range rv.MapKeys() {
+		rv.SetMapIndex(k, reflect.Value{})
+	}
+	iter := rv2.MapRange()
+	for iter.Next() {
+		rv.SetMapIndex(iter.Key(), iter.Value())
+	}
+}
+
+// needsMask reports whether elements of the given type have to be visited,
+// scalars without a tag are only changed in deny by default mode
+func (c *config) needsMask(elem reflect.Type, tag []string) bool {
+	if len(tag) != 0 && len(tag[0]) != 0 || c.denyByDefault {
+		return true
+	}
+
+	switch elem.Kind() {
+	case reflect.Struct, reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Array, reflect.Map:
+		return true
+	default:
+		return false
+	}
+}
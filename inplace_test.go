@@ -0,0 +1,102 @@
+package gmask
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMasker_MaskInPlace(t *testing.T) {
+	type card struct {
+		Number string `mask:"char,4"`
+	}
+	type user struct {
+		Name     string
+		Password string `mask:"zero"`
+		Age      int8   `mask:"zero"`
+		Token    []byte `mask:"char,3"`
+		Cards    []card
+		Ptr      *card
+		Tags     []string          `mask:"each:char,1"`
+		Attrs    map[string]string `mask:"each:zero"`
+		Any      any               `mask:"zero"`
+	}
+
+	shared := &card{Number: "4111"}
+	u := &user{
+		Name:     "foo",
+		Password: "bar",
+		Age:      18,
+		Token:    []byte("token"),
+		Cards:    []card{{Number: "1234"}},
+		Ptr:      shared,
+		Tags:     []string{"a", "b"},
+		Attrs:    map[string]string{"k": "v"},
+		Any:      "any",
+	}
+	cards := u.Cards
+
+	err := Default().MaskInPlace(u)
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", u.Name)
+	assert.Zero(t, u.Password)
+	assert.Zero(t, u.Age)
+	assert.Equal(t, []byte("***"), u.Token)
+	assert.Equal(t, "****", cards[0].Number)
+	assert.Equal(t, "****", shared.Number)
+	assert.Equal(t, []string{"*", "*"}, u.Tags)
+	assert.Equal(t, map[string]string{"k": ""}, u.Attrs)
+	assert.Zero(t, u.Any)
+
+	// cycles are visited once
+	type node struct {
+		Secret string `mask:"hash"`
+		Next   *node
+	}
+	n := &node{Secret: "secret"}
+	n.Next = n
+	assert.NoError(t, Default().MaskInPlace(n))
+	hashed, _ := MaskHashString("secret")
+	assert.Equal(t, hashed, n.Secret)
+
+	assert.Error(t, Default().MaskInPlace(user{}))
+	assert.Error(t, Default().MaskInPlace((*user)(nil)))
+}
+
+func TestMasker_MaskInPlaceDepth(t *testing.T) {
+	type node struct {
+		Secret string `mask:"zero"`
+		Count  int    `mask:"zero"`
+		Next   *node
+	}
+	newList := func() *node {
+		return &node{Secret: "a", Count: 1, Next: &node{Secret: "b", Count: 2, Next: &node{Secret: "c", Count: 3}}}
+	}
+
+	// both walks count the same depth
+	for depth := 1; depth < 12; depth++ {
+		m := NewWithDefaults().SetMaxDepth(depth)
+		_, err := m.Mask(newList())
+		errInPlace := m.MaskInPlace(newList())
+		assert.Equal(t, err == nil, errInPlace == nil, depth)
+	}
+}
+
+func TestMasker_MaskInPlaceAllocs(t *testing.T) {
+	type narrow struct {
+		A string
+	}
+	type wide struct {
+		A, B, C, D, E, F string
+		G, H, I          int
+		J                bool
+	}
+
+	// fields without rules cost nothing
+	m := NewWithDefaults()
+	allocs := func(v any) float64 {
+		return testing.AllocsPerRun(100, func() {
+			assert.NoError(t, m.MaskInPlace(v))
+		})
+	}
+	assert.Equal(t, allocs(&narrow{A: "a"}), allocs(&wide{A: "a", G: 1}))
+}
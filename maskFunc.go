@@ -1,35 +1,171 @@
 package gmask
 
 import (
+	"crypto/hmac"
 	"crypto/md5"
 	"crypto/sha1"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"hash"
 	"io"
 	"math"
-	"math/rand"
 	"reflect"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
 const (
-	MaskTypeZero   = "zero"
-	MaskTypeChar   = "char"
-	MaskTypeRandom = "rand"
-	MaskTypeHash   = "hash"
+	MaskTypeZero    = "zero"
+	MaskTypeChar    = "char"
+	MaskTypeRandom  = "rand"
+	MaskTypeHash    = "hash"
+	MaskTypeHMAC    = "hmac"
+	MaskTypeJSON    = "json"
+	MaskTypeOmit    = "omit"
+	MaskTypeLen     = "len"
+	MaskTypeRedact  = "redact"
+	MaskTypePartial = "partial"
 )
 
+// Redacted is the replacement used by MaskRedacted
+const Redacted = "[REDACTED]"
+
 var _ MaskAnyFunc = MaskZero
 
-// MaskZero will return the zero value of the given value
+// MaskZero will return the zero value of the given value,
+// it is also registered as omit so that the field can be dropped by omitempty
 //
-// Example: `mask:"zero"`
+// Example: `mask:"zero"` or `mask:"omit"`
 func MaskZero(value any, _ ...string) (any, error) {
 	return reflect.Zero(reflect.TypeOf(value)).Interface(), nil
 }
 
+var _ MaskAnyFunc = MaskRedacted
+
+// MaskRedacted will replace strings with [REDACTED]
+// and any other value with its zero value
+//
+// Example: `mask:"redact"`
+func MaskRedacted(value any, _ ...string) (any, error) {
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.String {
+		return reflect.ValueOf(Redacted).Convert(rv.Type()).Interface(), nil
+	}
+	return reflect.Zero(rv.Type()).Interface(), nil
+}
+
+// MaskFixed will generate a MaskAnyFunc which replaces values with
+// the given value, if the value can not be converted to the type of
+// the masked value, or overflows it, the zero value is used instead
+func MaskFixed(fixed any) MaskAnyFunc {
+	fv := reflect.ValueOf(fixed)
+	return func(value any, _ ...string) (any, error) {
+		rt := reflect.TypeOf(value)
+		if convertible(fv, rt) {
+			return fv.Convert(rt).Interface(), nil
+		}
+		return reflect.Zero(rt).Interface(), nil
+	}
+}
+
+// convertible reports whether v can be converted to rt without surprise,
+// only values of the same kind or between numbers are converted,
+// so that a number never turns into a string of runes, and numbers must
+// fit in rt instead of wrapping around
+func convertible(v reflect.Value, rt reflect.Type) bool {
+	if !v.IsValid() || !v.Type().ConvertibleTo(rt) {
+		return false
+	}
+	if isNumber(v.Kind()) && isNumber(rt.Kind()) {
+		return fits(v, rt)
+	}
+	return v.Kind() == rt.Kind()
+}
+
+// fits reports whether the number v keeps its value once converted to rt
+func fits(v reflect.Value, rt reflect.Type) bool {
+	switch {
+	case isComplex(v.Kind()) != isComplex(rt.Kind()):
+		// only complex numbers convert to each other
+		return false
+	case isComplex(rt.Kind()):
+		return !reflect.Zero(rt).OverflowComplex(v.Complex())
+	case rt.Kind() == reflect.Float32 || rt.Kind() == reflect.Float64:
+		return !isFloat(v.Kind()) || !reflect.Zero(rt).OverflowFloat(v.Float())
+	}
+
+	// integers must come back unchanged, which rejects fractions as well
+	back := v.Convert(rt).Convert(v.Type())
+	if !back.Equal(v) {
+		return false
+	}
+	// a negative integer turning into a large unsigned one comes back too
+	switch {
+	case isSigned(v.Kind()) && !isSigned(rt.Kind()) && !isFloat(rt.Kind()):
+		return v.Int() >= 0
+	case isFloat(v.Kind()) && !isSigned(rt.Kind()):
+		return v.Float() >= 0
+	case !isSigned(v.Kind()) && !isFloat(v.Kind()) && isSigned(rt.Kind()):
+		return v.Convert(rt).Int() >= 0
+	}
+	return true
+}
+
+func isSigned(k reflect.Kind) bool {
+	return reflect.Int <= k && k <= reflect.Int64
+}
+
+func isFloat(k reflect.Kind) bool {
+	return k == reflect.Float32 || k == reflect.Float64
+}
+
+func isComplex(k reflect.Kind) bool {
+	return k == reflect.Complex64 || k == reflect.Complex128
+}
+
+func isNumber(k reflect.Kind) bool {
+	return reflect.Int <= k && k <= reflect.Complex128
+}
+
+var _ MaskAnyFunc = MaskLen
+
+// MaskLen will keep nothing but the length of the given value,
+// slices keep their length with zero elements, maps keep their keys
+// with zero values, strings are replaced by the same number of *
+// and structs, having no length, are zeroed
+//
+// Example: `mask:"len"`
+func MaskLen(value any, _ ...string) (any, error) {
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Slice:
+		if rv.IsNil() {
+			return value, nil
+		}
+		return reflect.MakeSlice(rv.Type(), rv.Len(), rv.Len()).Interface(), nil
+	case reflect.Map:
+		if rv.IsNil() {
+			return value, nil
+		}
+		m := reflect.MakeMapWithSize(rv.Type(), rv.Len())
+		zero := reflect.Zero(rv.Type().Elem())
+		iter := rv.MapRange()
+		for iter.Next() {
+			m.SetMapIndex(iter.Key(), zero)
+		}
+		return m.Interface(), nil
+	case reflect.Array, reflect.Struct:
+		return reflect.Zero(rv.Type()).Interface(), nil
+	case reflect.String:
+		return reflect.ValueOf(strings.Repeat("*", rv.Len())).Convert(rv.Type()).Interface(), nil
+	default:
+		return reflect.Zero(rv.Type()).Interface(), fmt.Errorf("%w: len not support %s", ErrInvalidArgument, rv.Kind())
+	}
+}
+
 var _ MaskStringFunc = MaskCharString()
 
 // MaskCharString will generate a MaskStringFunc which
@@ -57,7 +193,10 @@ func MaskCharString(defaultMaskChar ...string) func(value string, arg ...string)
 			} else if len(arg[0]) != 0 {
 				length, err = strconv.Atoi(arg[0])
 				if err != nil {
-					return "", err
+					return "", fmt.Errorf("%w: %v", ErrInvalidArgument, err)
+				}
+				if length < 0 {
+					return "", fmt.Errorf("%w: length must be -1 or more, got %d", ErrInvalidArgument, length)
 				}
 			}
 		}
@@ -65,13 +204,40 @@ func MaskCharString(defaultMaskChar ...string) func(value string, arg ...string)
 			if len(arg[1]) == 1 {
 				maskChar = arg[1]
 			} else {
-				return "", fmt.Errorf("length of maskChar must equal to 1, got %d", len(arg))
+				return "", fmt.Errorf("%w: length of maskChar must equal to 1, got %d", ErrInvalidArgument, len(arg[1]))
 			}
 		}
 		return strings.Repeat(maskChar, length), nil
 	}
 }
 
+// PartialArgs declares the arguments of MaskPartialString
+var PartialArgs = []Arg{
+	{Name: "first", Type: ArgUint, Default: uint(0)},
+	{Name: "last", Type: ArgUint, Default: uint(4)},
+	{Name: "with", Type: ArgString, Default: "*", Check: func(value any) error {
+		if n := utf8.RuneCountInString(value.(string)); n != 1 {
+			return fmt.Errorf("length of maskChar must equal to 1, got %d", n)
+		}
+		return nil
+	}},
+}
+
+// MaskPartialString keeps the first and last characters of the given string
+// and replaces the others with maskChar, by default only the last 4 are kept,
+// strings too short to hide anything are masked entirely.
+// It is meant to be registered with RegMaskArgsFunc and PartialArgs.
+//
+// Example: `mask:"partial,[first],[last],[maskChar]"`
+func MaskPartialString(value string, args Args) (string, error) {
+	r := []rune(value)
+	first, last, with := int(args.Uint("first")), int(args.Uint("last")), args.String("with")
+	if first+last >= len(r) {
+		return strings.Repeat(with, len(r)), nil
+	}
+	return string(r[:first]) + strings.Repeat(with, len(r)-first-last) + string(r[len(r)-last:]), nil
+}
+
 var _ MaskStringFunc = MaskRandString
 
 // MaskRandString returns a random string
@@ -79,7 +245,11 @@ var _ MaskStringFunc = MaskRandString
 // will be equal to the length of the input string
 //
 // Example: `mask:"rand,[length]"`
-func MaskRandString(value string, arg ...string) (new string, err error) {
+func MaskRandString(value string, arg ...string) (string, error) {
+	return maskRandString(globalRand, value, arg...)
+}
+
+func maskRandString(r *lockedRand, value string, arg ...string) (new string, err error) {
 	length := 8
 	if len(arg) >= 1 {
 		if arg[0] == "-1" {
@@ -87,11 +257,14 @@ func MaskRandString(value string, arg ...string) (new string, err error) {
 		} else {
 			length, err = strconv.Atoi(arg[0])
 			if err != nil {
-				return "", err
+				return "", fmt.Errorf("%w: %v", ErrInvalidArgument, err)
+			}
+			if length < 0 {
+				return "", fmt.Errorf("%w: length must be -1 or more, got %d", ErrInvalidArgument, length)
 			}
 		}
 	}
-	return randString(length), nil
+	return randString(r, length), nil
 }
 
 const letterBytes = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
@@ -101,12 +274,12 @@ const (
 	letterIdxMax  = 63 / letterIdxBits   // # of letter indices fitting in 63 bits
 )
 
-func randString(length int) string {
+func randString(r *lockedRand, length int) string {
 	b := make([]byte, length)
 	// A randSrc.Int63() generates 63 random bits, enough for letterIdxMax characters!
-	for i, cache, remain := length-1, rand.Int63(), letterIdxMax; i >= 0; {
+	for i, cache, remain := length-1, r.Int63(), letterIdxMax; i >= 0; {
 		if remain == 0 {
-			cache, remain = rand.Int63(), letterIdxMax
+			cache, remain = r.Int63(), letterIdxMax
 		}
 		if idx := int(cache & letterIdxMask); idx < len(letterBytes) {
 			b[i] = letterBytes[idx]
@@ -154,75 +327,392 @@ func MaskHashString(value string, arg ...string) (string, error) {
 		}
 		return hex.EncodeToString(w.Sum(nil)), nil
 	default:
-		return "", fmt.Errorf("%s algorithm not support", algorithm)
+		return "", fmt.Errorf("%w: %s algorithm not support", ErrInvalidArgument, algorithm)
+	}
+}
+
+// MaskHMACString returns a mask giving the keyed hash of the string,
+// unlike MaskHashString its output can not be reversed by hashing guessed
+// values without the key, equal values still give equal outputs
+// supported algorithms: md5, sha1, sha256
+// default algorithm is sha256
+//
+// Example: `mask:"hmac,[algorithm]"`
+func MaskHMACString(key []byte) MaskStringFunc {
+	return func(value string, arg ...string) (string, error) {
+		algorithm := "sha256"
+		if len(arg) >= 1 {
+			algorithm = arg[0]
+		}
+
+		var h func() hash.Hash
+		switch algorithm {
+		case "md5":
+			h = md5.New
+		case "sha1":
+			h = sha1.New
+		case "sha256":
+			h = sha256.New
+		default:
+			return "", fmt.Errorf("%w: %s algorithm not support", ErrInvalidArgument, algorithm)
+		}
+
+		w := hmac.New(h, key)
+		if _, err := io.WriteString(w, value); err != nil {
+			return "", err
+		}
+		return hex.EncodeToString(w.Sum(nil)), nil
+	}
+}
+
+var _ MaskStringFunc = MaskJSONString
+
+// MaskJSONString parses the given string as JSON and replaces the value of
+// every listed key, at any depth, with the zero value of its JSON type,
+// it suits json.RawMessage fields and strings holding JSON documents
+//
+// Example: `mask:"json,password,token"`
+func MaskJSONString(value string, arg ...string) (string, error) {
+	if len(arg) == 0 || len(value) == 0 {
+		return value, nil
+	}
+
+	var doc any
+	d := json.NewDecoder(strings.NewReader(value))
+	d.UseNumber()
+	if err := d.Decode(&doc); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidArgument, err)
+	}
+
+	keys := make(map[string]struct{}, len(arg))
+	for _, k := range arg {
+		keys[k] = struct{}{}
+	}
+	b, err := json.Marshal(maskJSONKeys(doc, keys))
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func maskJSONKeys(doc any, keys map[string]struct{}) any {
+	switch v := doc.(type) {
+	case map[string]any:
+		for k, e := range v {
+			if _, hit := keys[k]; hit {
+				v[k] = zeroJSON(e)
+			} else {
+				v[k] = maskJSONKeys(e, keys)
+			}
+		}
+	case []any:
+		for i, e := range v {
+			v[i] = maskJSONKeys(e, keys)
+		}
+	}
+	return doc
+}
+
+func zeroJSON(v any) any {
+	switch v.(type) {
+	case string:
+		return ""
+	case json.Number:
+		return 0
+	case bool:
+		return false
+	default:
+		return nil
 	}
 }
 
 var _ MaskFloat64Func = MaskRandFloat64
 
-// MaskRandFloat64 returns a new random float64
+// MaskRandFloat64 returns a new random float64 in [min, max)
+// rounded down to the given digit, max defaults to 1
 //
 // Example: `mask:"rand,[max],[min],[digit]"`
 func MaskRandFloat64(_ float64, arg ...string) (float64, error) {
+	return maskRandFloat64(globalRand, arg...)
+}
+
+func maskRandFloat64(r *lockedRand, arg ...string) (float64, error) {
 	var (
 		max, min float64 = 1, 0
 		digit            = 0
+		err      error
 	)
 
 	switch len(arg) {
 	case 3:
-		digit, _ = strconv.Atoi(arg[2])
+		if len(arg[2]) != 0 {
+			if digit, err = strconv.Atoi(arg[2]); err != nil {
+				return 0, fmt.Errorf("%w: %v", ErrInvalidArgument, err)
+			}
+		}
 		fallthrough
 	case 2:
-		min, _ = strconv.ParseFloat(arg[1], 64)
+		if len(arg[1]) != 0 {
+			if min, err = strconv.ParseFloat(arg[1], 64); err != nil {
+				return 0, fmt.Errorf("%w: %v", ErrInvalidArgument, err)
+			}
+		}
 		fallthrough
 	case 1:
-		max, _ = strconv.ParseFloat(arg[0], 64)
+		if len(arg[0]) != 0 {
+			if max, err = strconv.ParseFloat(arg[0], 64); err != nil {
+				return 0, fmt.Errorf("%w: %v", ErrInvalidArgument, err)
+			}
+		}
+	case 0:
+	default:
+		return 0, fmt.Errorf("%w: expect at most 3 arguments, got %d", ErrInvalidArgument, len(arg))
+	}
+	if max <= min {
+		return 0, fmt.Errorf("%w: max %g must be greater than min %g", ErrInvalidArgument, max, min)
 	}
+
 	dd := math.Pow10(digit)
-	x := float64(int(rand.Float64()*(max-min)*dd + min*dd))
+	x := float64(int(r.Float64()*(max-min)*dd + min*dd))
 	return x / dd, nil
 }
 
 var _ MaskIntFunc = MaskRandInt
 
-// MaskRandInt returns a new random int
+// MaskRandInt returns a new random int in [min, max),
+// max defaults to math.MaxInt
 //
 // Example: `mask:"rand,[max],[min]"`
 func MaskRandInt(_ int, arg ...string) (int, error) {
+	return maskRandSigned[int](globalRand, strconv.IntSize, arg...)
+}
+
+var _ MaskUintFunc = MaskRandUint
+
+// MaskRandUint returns a new random uint in [min, max),
+// max defaults to math.MaxUint
+//
+// Example: `mask:"rand,[max],[min]"`
+func MaskRandUint(_ uint, arg ...string) (uint, error) {
+	return maskRandUnsigned[uint](globalRand, strconv.IntSize, arg...)
+}
+
+// maskRandSigned returns a random signed integer in [min, max) where both
+// bounds are parsed with the given bit size, so they can never exceed the
+// limits of the target kind. max defaults to the largest value of the kind.
+func maskRandSigned[T int | int8 | int16 | int32 | int64](r *lockedRand, bitSize int, arg ...string) (T, error) {
 	var (
-		max, min = math.MaxInt, 0
+		max, min int64 = 1<<(bitSize-1) - 1, 0
+		err      error
 	)
 
 	switch len(arg) {
 	case 2:
-		min, _ = strconv.Atoi(arg[1])
+		if len(arg[1]) != 0 {
+			if min, err = strconv.ParseInt(arg[1], 10, bitSize); err != nil {
+				return 0, fmt.Errorf("%w: %v", ErrInvalidArgument, err)
+			}
+		}
 		fallthrough
 	case 1:
-		max, _ = strconv.Atoi(arg[0])
+		if len(arg[0]) != 0 {
+			if max, err = strconv.ParseInt(arg[0], 10, bitSize); err != nil {
+				return 0, fmt.Errorf("%w: %v", ErrInvalidArgument, err)
+			}
+		}
+	case 0:
+	default:
+		return 0, fmt.Errorf("%w: expect at most 2 arguments, got %d", ErrInvalidArgument, len(arg))
+	}
+	if max <= min {
+		return 0, fmt.Errorf("%w: max %d must be greater than min %d", ErrInvalidArgument, max, min)
 	}
 
-	return rand.Intn(max-min) + min, nil
+	return T(min + int64(r.Uint64()%(uint64(max)-uint64(min)))), nil
 }
 
-var _ MaskUintFunc = MaskRandUint
-
-// MaskRandUint returns a new random uint
-// if not set max value, the
-//
-// Example: `mask:"rand,[max],[min]"`
-func MaskRandUint(_ uint, arg ...string) (uint, error) {
+// maskRandUnsigned is the unsigned counterpart of maskRandSigned.
+func maskRandUnsigned[T uint | uint8 | uint16 | uint32 | uint64](r *lockedRand, bitSize int, arg ...string) (T, error) {
 	var (
-		max, min uint64 = math.MaxUint64, 0
+		max, min uint64 = 1<<bitSize - 1, 0
+		err      error
 	)
 
 	switch len(arg) {
 	case 2:
-		min, _ = strconv.ParseUint(arg[1], 10, 64)
+		if len(arg[1]) != 0 {
+			if min, err = strconv.ParseUint(arg[1], 10, bitSize); err != nil {
+				return 0, fmt.Errorf("%w: %v", ErrInvalidArgument, err)
+			}
+		}
 		fallthrough
 	case 1:
-		max, _ = strconv.ParseUint(arg[0], 10, 64)
+		if len(arg[0]) != 0 {
+			if max, err = strconv.ParseUint(arg[0], 10, bitSize); err != nil {
+				return 0, fmt.Errorf("%w: %v", ErrInvalidArgument, err)
+			}
+		}
+	case 0:
+	default:
+		return 0, fmt.Errorf("%w: expect at most 2 arguments, got %d", ErrInvalidArgument, len(arg))
+	}
+	if max <= min {
+		return 0, fmt.Errorf("%w: max %d must be greater than min %d", ErrInvalidArgument, max, min)
 	}
 
-	return uint(rand.Uint64()%(max-min) + min), nil
+	return T(min + r.Uint64()%(max-min)), nil
+}
+
+var _ MaskInt8Func = MaskRandInt8
+
+// MaskRandInt8 returns a new random int8,
+// max defaults to math.MaxInt8
+//
+// Example: `mask:"rand,[max],[min]"`
+func MaskRandInt8(_ int8, arg ...string) (int8, error) {
+	return maskRandSigned[int8](globalRand, 8, arg...)
+}
+
+var _ MaskInt16Func = MaskRandInt16
+
+// MaskRandInt16 returns a new random int16,
+// max defaults to math.MaxInt16
+//
+// Example: `mask:"rand,[max],[min]"`
+func MaskRandInt16(_ int16, arg ...string) (int16, error) {
+	return maskRandSigned[int16](globalRand, 16, arg...)
+}
+
+var _ MaskInt32Func = MaskRandInt32
+
+// MaskRandInt32 returns a new random int32,
+// max defaults to math.MaxInt32
+//
+// Example: `mask:"rand,[max],[min]"`
+func MaskRandInt32(_ int32, arg ...string) (int32, error) {
+	return maskRandSigned[int32](globalRand, 32, arg...)
+}
+
+var _ MaskInt64Func = MaskRandInt64
+
+// MaskRandInt64 returns a new random int64,
+// max defaults to math.MaxInt64
+//
+// Example: `mask:"rand,[max],[min]"`
+func MaskRandInt64(_ int64, arg ...string) (int64, error) {
+	return maskRandSigned[int64](globalRand, 64, arg...)
+}
+
+var _ MaskUint8Func = MaskRandUint8
+
+// MaskRandUint8 returns a new random uint8,
+// max defaults to math.MaxUint8
+//
+// Example: `mask:"rand,[max],[min]"`
+func MaskRandUint8(_ uint8, arg ...string) (uint8, error) {
+	return maskRandUnsigned[uint8](globalRand, 8, arg...)
+}
+
+var _ MaskUint16Func = MaskRandUint16
+
+// MaskRandUint16 returns a new random uint16,
+// max defaults to math.MaxUint16
+//
+// Example: `mask:"rand,[max],[min]"`
+func MaskRandUint16(_ uint16, arg ...string) (uint16, error) {
+	return maskRandUnsigned[uint16](globalRand, 16, arg...)
+}
+
+var _ MaskUint32Func = MaskRandUint32
+
+// MaskRandUint32 returns a new random uint32,
+// max defaults to math.MaxUint32
+//
+// Example: `mask:"rand,[max],[min]"`
+func MaskRandUint32(_ uint32, arg ...string) (uint32, error) {
+	return maskRandUnsigned[uint32](globalRand, 32, arg...)
+}
+
+var _ MaskUint64Func = MaskRandUint64
+
+// MaskRandUint64 returns a new random uint64,
+// max defaults to math.MaxUint64
+//
+// Example: `mask:"rand,[max],[min]"`
+func MaskRandUint64(_ uint64, arg ...string) (uint64, error) {
+	return maskRandUnsigned[uint64](globalRand, 64, arg...)
+}
+
+var _ MaskFloat32Func = MaskRandFloat32
+
+// MaskRandFloat32 returns a new random float32,
+// arguments are the same as MaskRandFloat64
+//
+// Example: `mask:"rand,[max],[min],[digit]"`
+func MaskRandFloat32(_ float32, arg ...string) (float32, error) {
+	return maskRandFloat32(globalRand, arg...)
+}
+
+func maskRandFloat32(r *lockedRand, arg ...string) (float32, error) {
+	v, err := maskRandFloat64(r, arg...)
+	if err != nil {
+		return 0, err
+	}
+	if math.Abs(v) > math.MaxFloat32 {
+		return 0, fmt.Errorf("%w: %g overflows float32", ErrInvalidArgument, v)
+	}
+	return float32(v), nil
+}
+
+var _ MaskBoolFunc = MaskRandBool
+
+// MaskRandBool returns a new random bool
+//
+// Example: `mask:"rand"`
+func MaskRandBool(_ bool, _ ...string) (bool, error) {
+	return globalRand.Intn(2) == 1, nil
+}
+
+var _ MaskComplex128Func = MaskRandComplex128
+
+// MaskRandComplex128 returns a new random complex128,
+// both real and imaginary parts are generated as MaskRandFloat64 does
+//
+// Example: `mask:"rand,[max],[min],[digit]"`
+func MaskRandComplex128(_ complex128, arg ...string) (complex128, error) {
+	return maskRandComplex128(globalRand, arg...)
+}
+
+func maskRandComplex128(r *lockedRand, arg ...string) (complex128, error) {
+	re, err := maskRandFloat64(r, arg...)
+	if err != nil {
+		return 0, err
+	}
+	im, err := maskRandFloat64(r, arg...)
+	if err != nil {
+		return 0, err
+	}
+	return complex(re, im), nil
+}
+
+var _ MaskComplex64Func = MaskRandComplex64
+
+// MaskRandComplex64 returns a new random complex64,
+// both real and imaginary parts are generated as MaskRandFloat32 does
+//
+// Example: `mask:"rand,[max],[min],[digit]"`
+func MaskRandComplex64(_ complex64, arg ...string) (complex64, error) {
+	return maskRandComplex64(globalRand, arg...)
+}
+
+func maskRandComplex64(r *lockedRand, arg ...string) (complex64, error) {
+	re, err := maskRandFloat32(r, arg...)
+	if err != nil {
+		return 0, err
+	}
+	im, err := maskRandFloat32(r, arg...)
+	if err != nil {
+		return 0, err
+	}
+	return complex(re, im), nil
 }
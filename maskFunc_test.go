@@ -60,6 +60,14 @@ func TestMaskCharString(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "--------", maskedStr)
 	assert.Equal(t, "abcdef", originalStr)
+
+	// lengths below -1 are refused instead of panicking
+	_, err = starMasker(originalStr, "-2")
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+	_, err = Mask(struct {
+		S string `mask:"char,-2"`
+	}{S: originalStr})
+	assert.ErrorIs(t, err, ErrInvalidArgument)
 }
 
 func TestMaskRandString(t *testing.T) {
@@ -82,6 +90,14 @@ func TestMaskRandString(t *testing.T) {
 	assert.Equal(t, "abcdef", originalStr)
 	assert.NotEqual(t, originalStr, maskedStr)
 	assert.Len(t, maskedStr, len(originalStr))
+
+	// lengths below -1 are refused instead of panicking
+	_, err = MaskRandString(originalStr, "-2")
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+	_, err = Mask(struct {
+		S string `mask:"rand,-2"`
+	}{S: originalStr})
+	assert.ErrorIs(t, err, ErrInvalidArgument)
 }
 
 func TestMaskHashString(t *testing.T) {
@@ -159,3 +175,148 @@ func TestMaskRandUint(t *testing.T) {
 	assert.NotEqual(t, masked, origin)
 	assert.True(t, 10 <= masked && masked < 20)
 }
+
+func TestMaskRandSized(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		i8, err := MaskRandInt8(0)
+		assert.NoError(t, err)
+		assert.True(t, 0 <= i8 && i8 < math.MaxInt8)
+
+		i8, err = MaskRandInt8(0, "0", "-10")
+		assert.NoError(t, err)
+		assert.True(t, -10 <= i8 && i8 < 0)
+
+		u8, err := MaskRandUint8(0, "20", "10")
+		assert.NoError(t, err)
+		assert.True(t, 10 <= u8 && u8 < 20)
+
+		i64, err := MaskRandInt64(0, "", "4294967296")
+		assert.NoError(t, err)
+		assert.True(t, i64 >= 4294967296)
+
+		u64, err := MaskRandUint64(0)
+		assert.NoError(t, err)
+		assert.True(t, u64 < math.MaxUint64)
+
+		f32, err := MaskRandFloat32(0, "20", "10")
+		assert.NoError(t, err)
+		assert.True(t, 10 <= f32 && f32 < 20)
+
+		c128, err := MaskRandComplex128(0, "20", "10")
+		assert.NoError(t, err)
+		assert.True(t, 10 <= real(c128) && real(c128) < 20)
+		assert.True(t, 10 <= imag(c128) && imag(c128) < 20)
+	}
+
+	// bounds outside the kind are rejected instead of wrapping
+	_, err := MaskRandInt8(0, "300")
+	assert.Error(t, err)
+	_, err = MaskRandUint16(0, "-1")
+	assert.Error(t, err)
+	_, err = MaskRandInt32(0, "10", "20")
+	assert.Error(t, err)
+}
+
+func TestMaskJSONString(t *testing.T) {
+	masked, err := MaskJSONString(`{"user":{"name":"foo","password":"bar","pin":1234},"tokens":[{"token":"x"}]}`, "password", "pin", "token")
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"user":{"name":"foo","password":"","pin":0},"tokens":[{"token":""}]}`, masked)
+
+	masked, err = MaskJSONString(`{"password":"bar"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"password":"bar"}`, masked)
+
+	_, err = MaskJSONString(`{`, "password")
+	assert.Error(t, err)
+}
+
+func TestMaskRedacted(t *testing.T) {
+	type named string
+
+	masked, err := MaskRedacted("secret")
+	assert.NoError(t, err)
+	assert.Equal(t, Redacted, masked)
+
+	masked, err = MaskRedacted(named("secret"))
+	assert.NoError(t, err)
+	assert.Equal(t, named(Redacted), masked)
+
+	masked, err = MaskRedacted(57128)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, masked)
+}
+
+func TestMaskFixed(t *testing.T) {
+	fixed := MaskFixed(-1)
+
+	masked, err := fixed(int8(100))
+	assert.NoError(t, err)
+	assert.Equal(t, int8(-1), masked)
+
+	masked, err = fixed(1.5)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(-1), masked)
+
+	// numbers never turn into strings
+	masked, err = fixed("secret")
+	assert.NoError(t, err)
+	assert.Equal(t, "", masked)
+
+	// nor wrap around or lose their fraction
+	for _, value := range []any{uint(7), uint8(7), uint64(7)} {
+		masked, err = fixed(value)
+		assert.NoError(t, err)
+		assert.Zero(t, masked)
+	}
+	masked, err = MaskFixed(300)(int8(1))
+	assert.NoError(t, err)
+	assert.Equal(t, int8(0), masked)
+	masked, err = MaskFixed(uint64(math.MaxUint64))(int64(1))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), masked)
+	masked, err = MaskFixed(1.5)(1)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, masked)
+	masked, err = MaskFixed(1e300)(float32(1))
+	assert.NoError(t, err)
+	assert.Equal(t, float32(0), masked)
+	masked, err = MaskFixed(2.0)(uint16(1))
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(2), masked)
+}
+
+func TestMaskPartialString(t *testing.T) {
+	m := RegMaskArgsFunc(New(), MaskTypePartial, MaskPartialString, PartialArgs...)
+
+	masked, err := m.String("4111111111111111", MaskTypePartial)
+	assert.NoError(t, err)
+	assert.Equal(t, "************1111", masked)
+
+	masked, err = m.String("4111111111111111", MaskTypePartial, "6", "4", "x")
+	assert.NoError(t, err)
+	assert.Equal(t, "411111xxxxxx1111", masked)
+
+	masked, err = m.String("短い名前", MaskTypePartial, "1", "0")
+	assert.NoError(t, err)
+	assert.Equal(t, "短***", masked)
+
+	masked, err = m.String("abc", MaskTypePartial)
+	assert.NoError(t, err)
+	assert.Equal(t, "***", masked)
+
+	_, err = m.String("abc", MaskTypePartial, "0", "0", "--")
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+}
+
+func TestMaskRandInvalidArgument(t *testing.T) {
+	_, err := MaskRandInt(0, "x")
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+	_, err = MaskRandInt(0, "10", "20")
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+	_, err = MaskRandUint(0, "1", "2", "3")
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+	_, err = MaskRandFloat64(0, "x")
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+	_, err = MaskRandFloat64(0, "1", "1")
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+}
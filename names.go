@@ -0,0 +1,194 @@
+package gmask
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
+)
+
+// SensitiveNames are patterns matching names usually given to sensitive
+// fields, ready to use with SetNameHeuristics
+var SensitiveNames = []string{
+	"*password*", "*passwd*", "*secret*", "*token*", "*credential*",
+	"ssn", "*_ssn", "*_key", "*apikey*", "*privatekey*", "pin", "cvv", "cvc",
+}
+
+// SetNameHeuristics masks struct fields without mask tag and map values
+// with string keys, whose names match one of patterns, with tag.
+// Struct fields match by their Go name or their json name. Patterns are
+// matched case-insensitively and use the syntax of path.Match, such as
+// `*_key`. A field tagged `mask:"-"` is never masked by the heuristics,
+// nor is a value of a kind tag can not mask. An empty tag turns the
+// heuristics off.
+//
+// Example: SetNameHeuristics("redact", gmask.SensitiveNames...)
+func (m *Masker) SetNameHeuristics(tag string, patterns ...string) *Masker {
+	lowered := make([]string, len(patterns))
+	for i, pattern := range patterns {
+		lowered[i] = strings.ToLower(pattern)
+		if _, err := path.Match(lowered[i], ""); err != nil {
+			panic(fmt.Sprintf("gmask: bad name pattern %q: %v", pattern, err))
+		}
+	}
+
+	return m.update(func(c *config) {
+		c.nameTag, c.namePatterns = tag, lowered
+	})
+}
+
+// sensitiveName reports whether name matches a pattern of the heuristics
+func (c *config) sensitiveName(name string) bool {
+	if len(c.nameTag) == 0 {
+		return false
+	}
+
+	name = strings.ToLower(name)
+	for _, pattern := range c.namePatterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldTag returns the rule the policy gives to a field, its mask tag
+// otherwise, then the rule of its tag sources, or the tag of the name
+// heuristics for an untagged field with a sensitive name
+func (m *Masker) fieldTag(s *maskState, field reflect.StructField) string {
+	if tag, exist := s.policyTag(); exist {
+		return tag
+	}
+	return s.cfg.structTag(field)
+}
+
+// structTag returns the rule a field gets from its struct tags and name
+func (c *config) structTag(field reflect.StructField) string {
+	if tag := field.Tag.Get(c.tagName); len(tag) != 0 {
+		return tag
+	}
+	return c.untaggedTag(field)
+}
+
+// untaggedTag returns the rule a field without mask tag gets from its tag
+// sources and name, an empty tag leaves it to the kind defaults
+func (c *config) untaggedTag(field reflect.StructField) string {
+	if tag, exist := c.sourceTag(field); exist {
+		return tag
+	}
+	if len(c.nameTag) == 0 {
+		return ""
+	}
+
+	if !c.sensitiveName(field.Name) {
+		if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name == "-" || !c.sensitiveName(name) {
+			return ""
+		}
+	}
+	if !c.nameTagMasks(field.Type) {
+		return ""
+	}
+	return c.nameTag
+}
+
+// nameTagMasks reports whether the tag of the name heuristics can mask a
+// value of type rt, the heuristics skip values it can not mask, such as an
+// int64 TokenExpiry when the tag is char
+func (c *config) nameTagMasks(rt reflect.Type) bool {
+	v, err := c.parseViews(c.nameTag)
+	if err != nil {
+		// reported when the value is masked
+		return true
+	}
+
+	for _, r := range v.rules() {
+		masks := len(r) == 0
+		for _, p := range r {
+			masks = true
+			for _, st := range p {
+				masks = masks && c.masksKind(st[0], rt)
+			}
+			if masks {
+				break
+			}
+		}
+		if !masks {
+			return false
+		}
+	}
+	return true
+}
+
+// masksKind reports whether a mask named maskName can mask a value of type
+// rt, or the elements of a container of type rt. Unknown names are taken as
+// masking every kind so that they are still reported.
+func (c *config) masksKind(maskName string, rt reflect.Type) bool {
+	maskName = strings.TrimPrefix(maskName, eachPrefix)
+	if maskName == MaskTypePublic || !c.registered(maskName) || has(c.maskAnyFuncMap, maskName) || has(c.maskFieldFuncMap, maskName) {
+		return true
+	}
+	if isBytes(rt) {
+		return has(c.maskStringFuncMap, maskName)
+	}
+
+	switch rt.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return c.masksKind(maskName, rt.Elem())
+	case reflect.String:
+		return has(c.maskStringFuncMap, maskName)
+	case reflect.Int:
+		return has(c.maskIntFuncMap, maskName)
+	case reflect.Int8:
+		return has(c.maskInt8FuncMap, maskName) || has(c.maskIntFuncMap, maskName)
+	case reflect.Int16:
+		return has(c.maskInt16FuncMap, maskName) || has(c.maskIntFuncMap, maskName)
+	case reflect.Int32:
+		return has(c.maskInt32FuncMap, maskName) || has(c.maskIntFuncMap, maskName)
+	case reflect.Int64:
+		return has(c.maskInt64FuncMap, maskName) || has(c.maskIntFuncMap, maskName)
+	case reflect.Uint:
+		return has(c.maskUintFuncMap, maskName)
+	case reflect.Uint8:
+		return has(c.maskUint8FuncMap, maskName) || has(c.maskUintFuncMap, maskName)
+	case reflect.Uint16:
+		return has(c.maskUint16FuncMap, maskName) || has(c.maskUintFuncMap, maskName)
+	case reflect.Uint32:
+		return has(c.maskUint32FuncMap, maskName) || has(c.maskUintFuncMap, maskName)
+	case reflect.Uint64:
+		return has(c.maskUint64FuncMap, maskName) || has(c.maskUintFuncMap, maskName)
+	case reflect.Float32:
+		return has(c.maskFloat32FuncMap, maskName) || has(c.maskFloat64FuncMap, maskName)
+	case reflect.Float64:
+		return has(c.maskFloat64FuncMap, maskName)
+	case reflect.Bool:
+		return has(c.maskBoolFuncMap, maskName)
+	case reflect.Complex64:
+		return has(c.maskComplex64FuncMap, maskName)
+	case reflect.Complex128:
+		return has(c.maskComplex128FuncMap, maskName)
+	default:
+		// structs follow the tags of their fields, the kind held by an
+		// interface is only known when masking
+		return true
+	}
+}
+
+// keyRule returns the rule of the name heuristics for the value of a map
+// entry when the key is a sensitive name, a nil rule means the value is zeroed
+func (m *Masker) keyRule(s *maskState, key reflect.Value, rv reflect.Value) (hit bool, r rule, err error) {
+	if key.Kind() != reflect.String || !s.cfg.sensitiveName(key.String()) {
+		return false, nil, nil
+	}
+	if rv.Kind() == reflect.Interface && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if !s.cfg.nameTagMasks(rv.Type()) {
+		return false, nil, nil
+	}
+
+	v, err := s.cfg.parseViews(s.cfg.nameTag)
+	if err != nil {
+		return true, nil, err
+	}
+	return true, v.rule(s.view), nil
+}
@@ -0,0 +1,87 @@
+package gmask
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMasker_SetNameHeuristics(t *testing.T) {
+	type credentials struct {
+		User       string
+		Password   string
+		APIKey     string `json:"api_key"`
+		AuthToken  string `json:"auth"`
+		SessionKey string `mask:"-" json:"session_key"`
+		SSN        string `mask:"char,3"`
+		Extra      map[string]any
+	}
+	demo := credentials{
+		User:       "foo",
+		Password:   "bar",
+		APIKey:     "key",
+		AuthToken:  "token",
+		SessionKey: "session",
+		SSN:        "123-45-6789",
+		Extra:      map[string]any{"secret": "s", "name": "n", "db_key": 1},
+	}
+	expected := credentials{
+		User:       "foo",
+		Password:   Redacted,
+		APIKey:     Redacted,
+		AuthToken:  Redacted,
+		SessionKey: "session",
+		SSN:        "***",
+		Extra:      map[string]any{"secret": Redacted, "name": "n", "db_key": 0},
+	}
+
+	m := New().
+		RegMaskAnyFunc(MaskTypeRedact, MaskRedacted).
+		RegMaskStringFunc(MaskTypeChar, MaskCharString())
+	masked, err := m.Mask(demo)
+	assert.NoError(t, err)
+	assert.Equal(t, "bar", masked.(credentials).Password)
+	assert.Equal(t, "s", masked.(credentials).Extra["secret"])
+
+	m.SetNameHeuristics(MaskTypeRedact, SensitiveNames...)
+	masked, err = m.Mask(demo)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, masked)
+	assert.Equal(t, "s", demo.Extra["secret"])
+
+	err = m.MaskInPlace(&demo)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, demo)
+
+	assert.NoError(t, m.Validate(credentials{}))
+	m.SetNameHeuristics("nope", "password")
+	assert.ErrorIs(t, m.Validate(credentials{}), ErrUnknownStrategy)
+
+	assert.Panics(t, func() { m.SetNameHeuristics(MaskTypeRedact, "[a") })
+}
+
+func TestMasker_SetNameHeuristicsKind(t *testing.T) {
+	type session struct {
+		Token       string
+		TokenExpiry int64
+		Secrets     []string
+		SecretIDs   []int
+	}
+	demo := session{Token: "token", TokenExpiry: 42, Secrets: []string{"s"}, SecretIDs: []int{7}}
+
+	// values the tag can not mask are left to the other rules
+	m := New(WithDefaults(), WithNameHeuristics(MaskTypeChar, SensitiveNames...))
+	masked, err := m.Mask(demo)
+	assert.NoError(t, err)
+	assert.Equal(t, session{Token: "********", TokenExpiry: 42, Secrets: []string{"********"}, SecretIDs: []int{7}}, masked)
+	assert.NoError(t, m.Validate(session{}))
+
+	entries := map[string]any{"token": "t", "token_ttl": 60}
+	masked, err = m.Mask(entries)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"token": "********", "token_ttl": 60}, masked)
+
+	assert.NoError(t, m.MaskInPlace(&demo))
+	assert.Equal(t, int64(42), demo.TokenExpiry)
+	assert.Equal(t, "********", demo.Token)
+}
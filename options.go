@@ -0,0 +1,130 @@
+package gmask
+
+import "math/rand"
+
+// Option configures a masker built by New, options may be given in any
+// order. Settings can still be changed later with the Set methods.
+type Option func(o *options)
+
+type options struct {
+	tagName  string
+	strict   bool
+	maxDepth int
+	fallback MaskAnyFunc
+
+	defaults bool
+	rand     *lockedRand
+	hmacKey  []byte
+
+	nameTag      string
+	namePatterns []string
+
+	tagSources []tagSource
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{tagName: defaultTagName, strict: true}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// apply registers the masks asked by the options on m
+func (o *options) apply(m *Masker) *Masker {
+	if o.defaults {
+		registerDefaults(m)
+	}
+	if o.rand != nil {
+		o.rand.register(m)
+	}
+	if o.hmacKey != nil {
+		m.RegMaskStringFunc(MaskTypeHMAC, MaskHMACString(o.hmacKey))
+	}
+	for _, s := range o.tagSources {
+		m.RegTagSource(s.name, s.rules)
+	}
+	if len(o.nameTag) != 0 {
+		m.SetNameHeuristics(o.nameTag, o.namePatterns...)
+	}
+	return m
+}
+
+// WithTagName reads mask rules from the struct tags named name instead of
+// mask, such as `redact:"char"`
+func WithTagName(name string) Option {
+	if len(name) == 0 {
+		panic("gmask: empty tag name")
+	}
+	return func(o *options) {
+		o.tagName = name
+	}
+}
+
+// WithDefaults registers the built-in masks, see NewWithDefaults
+func WithDefaults() Option {
+	return func(o *options) {
+		o.defaults = true
+	}
+}
+
+// WithRandSource makes the rand masks draw from src instead of the global
+// source of math/rand, a seeded source gives reproducible outputs.
+// The masks are registered for every kind, WithDefaults is not needed.
+func WithRandSource(src rand.Source) Option {
+	if src == nil {
+		panic("gmask: nil rand source")
+	}
+	return func(o *options) {
+		o.rand = newLockedRand(src)
+	}
+}
+
+// WithHMACKey registers the hmac mask keyed with key, see MaskHMACString
+func WithHMACKey(key []byte) Option {
+	if len(key) == 0 {
+		panic("gmask: empty hmac key")
+	}
+	return func(o *options) {
+		o.hmacKey = append([]byte(nil), key...)
+	}
+}
+
+// WithTagSource reads the struct tags named name as well, see RegTagSource
+//
+// Example: WithTagSource("sensitive", map[string]string{"true": "char"})
+func WithTagSource(name string, rules map[string]string) Option {
+	return func(o *options) {
+		o.tagSources = append(o.tagSources, tagSource{name: name, rules: rules})
+	}
+}
+
+// WithStrict sets strict mode, see SetStrict
+func WithStrict(strict bool) Option {
+	return func(o *options) {
+		o.strict = strict
+	}
+}
+
+// WithMaxDepth limits how deep a value is walked, see SetMaxDepth
+func WithMaxDepth(depth int) Option {
+	return func(o *options) {
+		o.maxDepth = depth
+	}
+}
+
+// WithFallback replaces values failed to mask, see SetFallback
+func WithFallback(fallback MaskAnyFunc) Option {
+	return func(o *options) {
+		o.fallback = fallback
+	}
+}
+
+// WithNameHeuristics masks fields with sensitive names, see SetNameHeuristics
+//
+// Example: WithNameHeuristics("redact", gmask.SensitiveNames...)
+func WithNameHeuristics(tag string, patterns ...string) Option {
+	return func(o *options) {
+		o.nameTag, o.namePatterns = tag, patterns
+	}
+}
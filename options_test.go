@@ -0,0 +1,95 @@
+package gmask
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew_Options(t *testing.T) {
+	type demo struct {
+		Name     string `log:"char,2" mask:"zero"`
+		Password string
+		Code     int `log:"rand,100"`
+		Nested   *demo
+	}
+
+	m := New(
+		WithTagName("log"),
+		WithDefaults(),
+		WithNameHeuristics(MaskTypeRedact, SensitiveNames...),
+		WithFallback(MaskZero),
+		WithMaxDepth(2),
+	)
+	masked, err := m.Mask(demo{Name: "foo", Password: "bar", Code: 7})
+	assert.NoError(t, err)
+	assert.Equal(t, "**", masked.(demo).Name)
+	assert.Equal(t, Redacted, masked.(demo).Password)
+	assert.Less(t, masked.(demo).Code, 100)
+
+	_, err = m.Mask(demo{Nested: &demo{Nested: &demo{}}})
+	assert.Error(t, err)
+
+	// not strict, an unknown mask leaves the value as it is
+	masked, err = New(WithStrict(false)).String("foo", "nope")
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", masked)
+	_, err = New().String("foo", "nope")
+	assert.ErrorIs(t, err, ErrUnknownStrategy)
+
+	assert.Panics(t, func() { WithTagName("") })
+}
+
+func TestNew_WithRandSource(t *testing.T) {
+	type demo struct {
+		S string  `mask:"rand"`
+		I int8    `mask:"rand"`
+		F float64 `mask:"rand,10,0,2"`
+		B []bool  `mask:"each:rand"`
+	}
+
+	// the source is used whatever the order of the options
+	a := New(WithRandSource(rand.NewSource(1)), WithDefaults())
+	b := New(WithDefaults(), WithRandSource(rand.NewSource(1)))
+	for i := 0; i < 3; i++ {
+		va, err := a.Mask(demo{B: make([]bool, 8)})
+		assert.NoError(t, err)
+		vb, err := b.Mask(demo{B: make([]bool, 8)})
+		assert.NoError(t, err)
+		assert.Equal(t, va, vb)
+		assert.Len(t, va.(demo).S, 8)
+	}
+
+	// the rand masks are registered without the defaults
+	m := New(WithRandSource(rand.NewSource(1)))
+	_, err := m.Int(1, MaskTypeRandom, "10")
+	assert.NoError(t, err)
+	_, err = m.Int(1, MaskTypeZero)
+	assert.ErrorIs(t, err, ErrUnknownStrategy)
+}
+
+func TestNew_WithHMACKey(t *testing.T) {
+	m := New(WithHMACKey([]byte("key")))
+	a, err := m.String("foo", MaskTypeHMAC)
+	assert.NoError(t, err)
+	b, err := m.String("foo", MaskTypeHMAC)
+	assert.NoError(t, err)
+	assert.Equal(t, a, b)
+	assert.Len(t, a, 64)
+
+	other, err := New(WithHMACKey([]byte("other"))).String("foo", MaskTypeHMAC)
+	assert.NoError(t, err)
+	assert.NotEqual(t, a, other)
+	hash, err := MaskHashString("foo")
+	assert.NoError(t, err)
+	assert.NotEqual(t, hash, a)
+
+	a, err = m.String("foo", MaskTypeHMAC, "md5")
+	assert.NoError(t, err)
+	assert.Len(t, a, 32)
+	_, err = m.String("foo", MaskTypeHMAC, "crc")
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+
+	assert.Panics(t, func() { WithHMACKey(nil) })
+}
@@ -0,0 +1,218 @@
+package gmask
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Policy gives mask rules to the fields of types which can not be tagged,
+// such as generated or third party types. Its rules take precedence over
+// the mask tags of the fields.
+//
+// A policy is read from JSON or YAML:
+//
+//	{"types": [{"type": "github.com/x/pb.User", "fields": {"Email": "email", "Cards[*].Number": "pan"}}]}
+type Policy struct {
+	Types []TypePolicy `json:"types" yaml:"types"`
+}
+
+// TypePolicy holds the rules of a type
+type TypePolicy struct {
+	// Type is the full name of the type, such as github.com/x/pb.User,
+	// see TypeName
+	Type string `json:"type" yaml:"type"`
+	// Fields maps field paths relative to the type to rules written like
+	// mask tags. A path is a chain of field names, [*] standing for the
+	// elements of a slice, array or map, such as Cards[*].Number.
+	Fields map[string]string `json:"fields" yaml:"fields"`
+}
+
+// TypeName returns the name of the type of v used by policies, v may also
+// be a reflect.Type. Pointers are named after the type they point to.
+func TypeName(v any) string {
+	rt, ok := v.(reflect.Type)
+	if !ok {
+		rt = reflect.TypeOf(v)
+	}
+	for rt != nil && rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	if rt == nil {
+		return ""
+	}
+	if rt.PkgPath() == "" {
+		return rt.String()
+	}
+	return rt.PkgPath() + "." + rt.Name()
+}
+
+// NewPolicy returns an empty policy to be built with Field
+func NewPolicy() *Policy {
+	return &Policy{}
+}
+
+// Field adds a rule for the field at path of the type named typeName
+//
+// Example: NewPolicy().Field(gmask.TypeName(pb.User{}), "Cards[*].Number", "partial")
+func (p *Policy) Field(typeName string, path string, tag string) *Policy {
+	for i := range p.Types {
+		if p.Types[i].Type == typeName {
+			if p.Types[i].Fields == nil {
+				p.Types[i].Fields = make(map[string]string)
+			}
+			p.Types[i].Fields[path] = tag
+			return p
+		}
+	}
+
+	p.Types = append(p.Types, TypePolicy{Type: typeName, Fields: map[string]string{path: tag}})
+	return p
+}
+
+// ParsePolicy reads a policy from JSON or YAML, either a whole policy or
+// the policy of a single type such as {"type": …, "fields": {…}}.
+// Unknown keys and a policy without types are refused, so a mistyped
+// policy fails instead of silently masking nothing.
+func ParsePolicy(data []byte) (*Policy, error) {
+	var keys map[string]yaml.Node
+	if err := yaml.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("%w: policy: %v", ErrInvalidArgument, err)
+	}
+
+	p := new(Policy)
+	var target any = p
+	if _, single := keys["type"]; single {
+		p.Types = make([]TypePolicy, 1)
+		target = &p.Types[0]
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(target); err != nil {
+		return nil, fmt.Errorf("%w: policy: %v", ErrInvalidArgument, err)
+	}
+
+	if len(p.Types) == 0 {
+		return nil, fmt.Errorf("%w: policy: no types", ErrInvalidArgument)
+	}
+	for _, tp := range p.Types {
+		if len(tp.Type) == 0 {
+			return nil, fmt.Errorf("%w: policy: type without name", ErrInvalidArgument)
+		}
+	}
+	return p, nil
+}
+
+// LoadPolicyFile reads the policy in the JSON or YAML file name and sets it,
+// see SetPolicy
+func (m *Masker) LoadPolicyFile(name string) error {
+	return m.ReloadPolicy(PolicyFile(name))
+}
+
+// typeRules maps type names to field paths to rules
+type typeRules map[string]map[string]string
+
+// SetPolicy replaces the policy of the masker, nil removes it. Every rule is
+// parsed and, in strict mode, must name registered masks, otherwise the
+// policy is refused and the masker is left unchanged. The policy can be
+// replaced while masking, a Mask call keeps using the policy it started with.
+func (m *Masker) SetPolicy(p *Policy) error {
+	if p == nil {
+		m.policy.Store(nil)
+		return nil
+	}
+
+	policy := make(typeRules, len(p.Types))
+	for _, tp := range p.Types {
+		fields := policy[tp.Type]
+		if fields == nil {
+			fields = make(map[string]string, len(tp.Fields))
+			policy[tp.Type] = fields
+		}
+		for path, tag := range tp.Fields {
+			if err := m.checkPolicyRule(tag); err != nil {
+				return fmt.Errorf("policy of %s.%s: %w", tp.Type, path, err)
+			}
+			fields[path] = tag
+		}
+	}
+
+	m.policy.Store(&policy)
+	return nil
+}
+
+// checkPolicyRule parses a rule of a policy and checks its mask names
+func (m *Masker) checkPolicyRule(tag string) error {
+	c := m.c()
+	v, err := c.parseViews(tag)
+	if err != nil {
+		return err
+	}
+	if !c.strict {
+		return nil
+	}
+
+	for _, r := range v.rules() {
+		for _, p := range r {
+			for _, st := range p {
+				name := strings.TrimPrefix(st[0], eachPrefix)
+				if len(name) != 0 && name != MaskTypePublic && !c.registered(name) {
+					return fmt.Errorf("%w: %s", ErrUnknownStrategy, name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// policyFrame holds the rules of a struct type being walked
+type policyFrame struct {
+	fields map[string]string
+	// base is the length of the path at the struct
+	base int
+}
+
+// enterPolicy starts applying the policy of the struct type rt to the
+// fields walked next, the returned function stops it
+func (s *maskState) enterPolicy(rt reflect.Type) (leave func()) {
+	if s.policy == nil {
+		return func() {}
+	}
+	fields, exist := (*s.policy)[TypeName(rt)]
+	if !exist {
+		return func() {}
+	}
+
+	s.policies = append(s.policies, policyFrame{fields: fields, base: len(s.path)})
+	return func() {
+		s.policies = s.policies[:len(s.policies)-1]
+	}
+}
+
+// policyTag returns the rule the policy gives to the value at the path of
+// the walk, the innermost type with a rule for it wins
+func (s *maskState) policyTag() (string, bool) {
+	for i := len(s.policies) - 1; i >= 0; i-- {
+		frame := s.policies[i]
+		if tag, exist := frame.fields[s.relativePath(frame.base)]; exist {
+			return tag, true
+		}
+	}
+	return "", false
+}
+
+// relativePath returns the path of the walk from the segment at base,
+// indexes and keys are written [*]
+func (s *maskState) relativePath(base int) string {
+	segments := make([]string, len(s.path)-base)
+	for i, segment := range s.path[base:] {
+		if strings.HasPrefix(segment, "[") {
+			segment = "[*]"
+		}
+		segments[i] = segment
+	}
+	return joinPath(segments)
+}
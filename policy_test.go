@@ -0,0 +1,139 @@
+package gmask
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type policyCard struct {
+	Number string
+	CVV    string `mask:"zero"`
+}
+
+type policyUser struct {
+	Name  string
+	Email string `mask:"zero"`
+	Cards []policyCard
+	Attrs map[string]policyCard
+}
+
+func TestMasker_SetPolicy(t *testing.T) {
+	demo := policyUser{
+		Name:  "foo",
+		Email: "foo@bar.com",
+		Cards: []policyCard{{Number: "4111111111111111", CVV: "123"}},
+		Attrs: map[string]policyCard{"k": {Number: "4222222222222222", CVV: "456"}},
+	}
+	expected := policyUser{
+		Name:  "foo",
+		Email: "***",
+		Cards: []policyCard{{Number: "************1111"}},
+		Attrs: map[string]policyCard{"k": {Number: "4222222222222222"}},
+	}
+
+	assert.Equal(t, "github.com/asjdf/gmask.policyUser", TypeName(&demo))
+	assert.Equal(t, "[]string", TypeName([]string{}))
+
+	m := New().
+		RegMaskAnyFunc(MaskTypeZero, MaskZero).
+		RegMaskStringFunc(MaskTypeChar, MaskCharString())
+	RegMaskArgsFunc(m, MaskTypePartial, MaskPartialString, PartialArgs...)
+
+	p := NewPolicy().
+		Field(TypeName(policyUser{}), "Email", "char,3").
+		Field(TypeName(policyUser{}), "Cards[*].Number", "partial")
+	assert.NoError(t, m.SetPolicy(p))
+	masked, err := m.Mask(demo)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, masked)
+	assert.NoError(t, m.Validate(policyUser{}))
+
+	// the same policy read from a file
+	assert.NoError(t, m.SetPolicy(nil))
+	masked, err = m.Mask(demo)
+	assert.NoError(t, err)
+	assert.Equal(t, "", masked.(policyUser).Email)
+
+	name := filepath.Join(t.TempDir(), "policy.yaml")
+	assert.NoError(t, os.WriteFile(name, []byte(`
+types:
+  - type: github.com/asjdf/gmask.policyUser
+    fields:
+      Email: char,3
+      Cards[*].Number: partial
+`), 0o600))
+	assert.NoError(t, m.LoadPolicyFile(name))
+	masked, err = m.Mask(demo)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, masked)
+
+	err = m.MaskInPlace(&demo)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, demo)
+
+	p, err = ParsePolicy([]byte(`{"types": [{"type": "github.com/asjdf/gmask.policyCard", "fields": {"CVV": "-"}}]}`))
+	assert.NoError(t, err)
+	assert.Equal(t, &Policy{Types: []TypePolicy{{Type: "github.com/asjdf/gmask.policyCard", Fields: map[string]string{"CVV": "-"}}}}, p)
+
+	err = m.SetPolicy(NewPolicy().Field("x.T", "A", "nope"))
+	assert.ErrorIs(t, err, ErrUnknownStrategy)
+	err = m.SetPolicy(NewPolicy().Field("x.T", "A", "char("))
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+	_, err = ParsePolicy([]byte(`types: 1`))
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+	assert.Error(t, m.LoadPolicyFile(filepath.Join(t.TempDir(), "missing.json")))
+
+	// a refused policy leaves the previous one in place
+	masked, err = m.Mask(policyUser{Email: "foo@bar.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, "***", masked.(policyUser).Email)
+}
+
+func TestParsePolicy(t *testing.T) {
+	// the policy of a single type
+	p, err := ParsePolicy([]byte(`{"type": "github.com/asjdf/gmask.policyUser", "fields": {"Email": "char,3"}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, NewPolicy().Field("github.com/asjdf/gmask.policyUser", "Email", "char,3"), p)
+
+	// mistyped or empty policies mask nothing, they are refused
+	for _, policy := range []string{
+		``,
+		`{}`,
+		`types: []`,
+		`typs: [{type: x.T, fields: {A: zero}}]`,
+		`types: [{type: x.T, field: {A: zero}}]`,
+		`{"type": "x.T", "fields": {"A": "zero"}, "extra": 1}`,
+		`types: [{fields: {A: zero}}]`,
+		`[1, 2]`,
+	} {
+		_, err = ParsePolicy([]byte(policy))
+		assert.ErrorIs(t, err, ErrInvalidArgument, policy)
+	}
+
+	// a type parsed without fields can still be given some
+	p, err = ParsePolicy([]byte(`types: [{type: x.T}]`))
+	assert.NoError(t, err)
+	p.Field("x.T", "A", "zero")
+	assert.Equal(t, map[string]string{"A": "zero"}, p.Types[0].Fields)
+}
+
+func TestMasker_SetPolicyAlternative(t *testing.T) {
+	type account struct {
+		L     []string `mask:"each:hash,bad ?? zero"`
+		Email string
+	}
+
+	// the failed alternative must not leave its index on the path
+	m := NewWithDefaults()
+	assert.NoError(t, m.SetPolicy(NewPolicy().Field(TypeName(account{}), "Email", "char,3")))
+	masked, err := m.Mask(account{L: []string{"foo"}, Email: "foo@bar.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, account{Email: "***"}, masked)
+
+	demo := account{L: []string{"foo"}, Email: "foo@bar.com"}
+	assert.NoError(t, m.MaskInPlace(&demo))
+	assert.Equal(t, account{Email: "***"}, demo)
+}
@@ -0,0 +1,111 @@
+package gmask
+
+import (
+	"math/rand"
+	"strconv"
+	"sync"
+)
+
+// lockedRand is the random source of the rand masks, it is safe for
+// concurrent use. The zero value draws from the global source of math/rand.
+type lockedRand struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+// globalRand is used by the MaskRand functions
+var globalRand = new(lockedRand)
+
+func newLockedRand(src rand.Source) *lockedRand {
+	return &lockedRand{r: rand.New(src)}
+}
+
+func (l *lockedRand) Int63() int64 {
+	if l.r == nil {
+		return rand.Int63()
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Int63()
+}
+
+func (l *lockedRand) Uint64() uint64 {
+	if l.r == nil {
+		return rand.Uint64()
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Uint64()
+}
+
+func (l *lockedRand) Float64() float64 {
+	if l.r == nil {
+		return rand.Float64()
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Float64()
+}
+
+func (l *lockedRand) Intn(n int) int {
+	if l.r == nil {
+		return rand.Intn(n)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Intn(n)
+}
+
+// register registers the rand masks of every kind drawing from l,
+// they take the same arguments as the MaskRand functions
+func (l *lockedRand) register(m *Masker) *Masker {
+	return m.
+		RegMaskStringFunc(MaskTypeRandom, func(value string, arg ...string) (string, error) {
+			return maskRandString(l, value, arg...)
+		}).
+		RegMaskIntFunc(MaskTypeRandom, func(_ int, arg ...string) (int, error) {
+			return maskRandSigned[int](l, strconv.IntSize, arg...)
+		}).
+		RegMaskFloat64Func(MaskTypeRandom, func(_ float64, arg ...string) (float64, error) {
+			return maskRandFloat64(l, arg...)
+		}).
+		RegMaskUintFunc(MaskTypeRandom, func(_ uint, arg ...string) (uint, error) {
+			return maskRandUnsigned[uint](l, strconv.IntSize, arg...)
+		}).
+		RegMaskFloat32Func(MaskTypeRandom, func(_ float32, arg ...string) (float32, error) {
+			return maskRandFloat32(l, arg...)
+		}).
+		RegMaskInt8Func(MaskTypeRandom, func(_ int8, arg ...string) (int8, error) {
+			return maskRandSigned[int8](l, 8, arg...)
+		}).
+		RegMaskInt16Func(MaskTypeRandom, func(_ int16, arg ...string) (int16, error) {
+			return maskRandSigned[int16](l, 16, arg...)
+		}).
+		RegMaskInt32Func(MaskTypeRandom, func(_ int32, arg ...string) (int32, error) {
+			return maskRandSigned[int32](l, 32, arg...)
+		}).
+		RegMaskInt64Func(MaskTypeRandom, func(_ int64, arg ...string) (int64, error) {
+			return maskRandSigned[int64](l, 64, arg...)
+		}).
+		RegMaskUint8Func(MaskTypeRandom, func(_ uint8, arg ...string) (uint8, error) {
+			return maskRandUnsigned[uint8](l, 8, arg...)
+		}).
+		RegMaskUint16Func(MaskTypeRandom, func(_ uint16, arg ...string) (uint16, error) {
+			return maskRandUnsigned[uint16](l, 16, arg...)
+		}).
+		RegMaskUint32Func(MaskTypeRandom, func(_ uint32, arg ...string) (uint32, error) {
+			return maskRandUnsigned[uint32](l, 32, arg...)
+		}).
+		RegMaskUint64Func(MaskTypeRandom, func(_ uint64, arg ...string) (uint64, error) {
+			return maskRandUnsigned[uint64](l, 64, arg...)
+		}).
+		RegMaskBoolFunc(MaskTypeRandom, func(_ bool, _ ...string) (bool, error) {
+			return l.Intn(2) == 1, nil
+		}).
+		RegMaskComplex64Func(MaskTypeRandom, func(_ complex64, arg ...string) (complex64, error) {
+			return maskRandComplex64(l, arg...)
+		}).
+		RegMaskComplex128Func(MaskTypeRandom, func(_ complex128, arg ...string) (complex128, error) {
+			return maskRandComplex128(l, arg...)
+		})
+}
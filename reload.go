@@ -0,0 +1,103 @@
+package gmask
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// PolicySource opens the current content of a policy in JSON or YAML
+type PolicySource func() (io.ReadCloser, error)
+
+// PolicyFile is the PolicySource reading the file name
+func PolicyFile(name string) PolicySource {
+	return func() (io.ReadCloser, error) {
+		return os.Open(name)
+	}
+}
+
+func (source PolicySource) read() ([]byte, error) {
+	r, err := source()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
+
+// ReloadPolicy reads the policy from source and sets it, see SetPolicy.
+// A policy which can not be read or is invalid is refused and the current
+// one is kept.
+func (m *Masker) ReloadPolicy(source PolicySource) error {
+	data, err := source.read()
+	if err != nil {
+		return err
+	}
+
+	return m.setPolicyData(data)
+}
+
+func (m *Masker) setPolicyData(data []byte) error {
+	p, err := ParsePolicy(data)
+	if err != nil {
+		return err
+	}
+	return m.SetPolicy(p)
+}
+
+// WatchPolicy loads the policy from source, then reloads it every interval
+// until ctx is done. The first load must succeed, later failures are
+// reported to onError, which may be nil, and keep the current policy.
+// The policy is only parsed again when the content of source changed, so
+// an invalid policy is reported once.
+//
+// Example:
+//
+//	err := masker.WatchPolicy(ctx, gmask.PolicyFile("policy.yaml"), 10*time.Second, func(err error) {
+//		log.Printf("keep current mask policy: %v", err)
+//	})
+func (m *Masker) WatchPolicy(ctx context.Context, source PolicySource, interval time.Duration, onError func(error)) error {
+	if interval <= 0 {
+		return fmt.Errorf("%w: watch interval %s", ErrInvalidArgument, interval)
+	}
+
+	data, err := source.read()
+	if err != nil {
+		return err
+	}
+	if err = m.setPolicyData(data); err != nil {
+		return err
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		last := data
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			data, err := source.read()
+			if err == nil {
+				if bytes.Equal(data, last) {
+					continue
+				}
+				last = data
+				err = m.setPolicyData(data)
+			}
+			if err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}()
+
+	return nil
+}
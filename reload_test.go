@@ -0,0 +1,99 @@
+package gmask
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func policyOf(field string, tag string) string {
+	return `{"types": [{"type": "github.com/asjdf/gmask.policyUser", "fields": {"` + field + `": "` + tag + `"}}]}`
+}
+
+// writePolicy replaces the file at once, so it is never read half written
+func writePolicy(t *testing.T, name string, policy string) {
+	assert.NoError(t, os.WriteFile(name+".tmp", []byte(policy), 0o600))
+	assert.NoError(t, os.Rename(name+".tmp", name))
+}
+
+func TestMasker_WatchPolicy(t *testing.T) {
+	m := New().
+		RegMaskAnyFunc(MaskTypeZero, MaskZero).
+		RegMaskStringFunc(MaskTypeChar, MaskCharString())
+	demo := policyUser{Name: "foo", Email: "foo@bar.com"}
+
+	name := filepath.Join(t.TempDir(), "policy.json")
+	writePolicy(t, name, policyOf("Name", "char,3"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errs := make(chan error, 10)
+	err := m.WatchPolicy(ctx, PolicyFile(name), time.Millisecond, func(err error) { errs <- err })
+	assert.NoError(t, err)
+	masked, err := m.Mask(demo)
+	assert.NoError(t, err)
+	assert.Equal(t, policyUser{Name: "***"}, masked)
+
+	// an invalid policy is reported and the current one kept
+	writePolicy(t, name, policyOf("Name", "nope"))
+	select {
+	case err = <-errs:
+		assert.ErrorIs(t, err, ErrUnknownStrategy)
+	case <-time.After(time.Second):
+		t.Fatal("invalid policy not reported")
+	}
+	masked, err = m.Mask(demo)
+	assert.NoError(t, err)
+	assert.Equal(t, policyUser{Name: "***"}, masked)
+
+	writePolicy(t, name, policyOf("Email", "char,2"))
+	assert.Eventually(t, func() bool {
+		masked, err := m.Mask(demo)
+		return err == nil && masked.(policyUser).Name == "foo" && masked.(policyUser).Email == "**"
+	}, time.Second, time.Millisecond)
+	assert.Empty(t, errs)
+
+	err = New().WatchPolicy(ctx, PolicyFile(filepath.Join(t.TempDir(), "missing")), time.Second, nil)
+	assert.Error(t, err)
+	err = New().WatchPolicy(ctx, PolicyFile(name), 0, nil)
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+}
+
+func TestMasker_ReloadPolicy(t *testing.T) {
+	m := New().
+		RegMaskAnyFunc(MaskTypeZero, MaskZero).
+		RegMaskStringFunc(MaskTypeChar, MaskCharString())
+	source := func(policy string) PolicySource {
+		return func() (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader(policy)), nil
+		}
+	}
+
+	assert.NoError(t, m.ReloadPolicy(source(policyOf("Name", "char,3"))))
+	assert.ErrorIs(t, m.ReloadPolicy(source("types: [")), ErrInvalidArgument)
+
+	// a Mask call keeps the policy it started with
+	m.RegMaskFieldFunc("swap", func(fc FieldContext, value any, arg ...string) (any, error) {
+		assert.NoError(t, m.ReloadPolicy(source(policyOf("Name", "zero"))))
+		return value, nil
+	})
+	masked, err := m.Mask(struct {
+		Swap  string `mask:"swap"`
+		Users []policyUser
+	}{Swap: "swap", Users: []policyUser{{Name: "foo"}, {Name: "bar"}}})
+	assert.NoError(t, err)
+	assert.Equal(t, []policyUser{{Name: "***"}, {Name: "***"}}, masked.(struct {
+		Swap  string `mask:"swap"`
+		Users []policyUser
+	}).Users)
+
+	masked, err = m.Mask(policyUser{Name: "foo"})
+	assert.NoError(t, err)
+	assert.Equal(t, policyUser{}, masked)
+}
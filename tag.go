@@ -0,0 +1,361 @@
+package gmask
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// builtinArgNames names the arguments of the built-in masks for the named
+// argument syntax, a position may have several names separated by |
+var builtinArgNames = map[string][]string{
+	MaskTypeChar:    {"len|length", "with|char"},
+	MaskTypeRandom:  {"max|len|length", "min", "digit"},
+	MaskTypeHash:    {"algorithm|alg"},
+	MaskTypePartial: {"first", "last", "with|char"},
+}
+
+// RegMaskArgNames names the positional arguments of a mask so that they
+// can be given by name in a tag, a position may have several names
+// separated by |. Masks registered with RegMaskArgsFunc are named from
+// their schema and the built-in masks are named already.
+//
+// Example: RegMaskArgNames("char", "len", "with") allows `mask:"char(with='-', len=3)"`
+func (m *Masker) RegMaskArgNames(maskName string, names ...string) *Masker {
+	return m.update(func(c *config) {
+		c.argNames[maskName] = names
+		c.tags = new(sync.Map)
+	})
+}
+
+// argPosition returns the position of the named argument of a mask
+func (c *config) argPosition(maskName string, name string) (int, bool) {
+	names, exist := c.argNames[maskName]
+	if !exist {
+		names = builtinArgNames[maskName]
+	}
+
+	for i, n := range names {
+		for _, alias := range strings.Split(n, "|") {
+			if alias == name {
+				return i, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// rule is a parsed tag, its pipelines are tried in order until one succeeds
+//
+// Example: `mask:"email|hash ?? char"` first tries to hash the result of
+// email and falls back to char if either fails
+type rule []pipeline
+
+// pipeline is a list of masks, each one masking the result of the previous one
+type pipeline []stage
+
+// stage is a single mask: its name followed by its arguments
+type stage = []string
+
+// parsedViews is the memoized result of compileViews
+type parsedViews struct {
+	views *views
+	err   error
+}
+
+// parseViews parses a mask tag, each distinct tag is only parsed once.
+func (c *config) parseViews(tag string) (*views, error) {
+	if parsed, cached := c.tags.Load(tag); cached {
+		return parsed.(parsedViews).views, parsed.(parsedViews).err
+	}
+
+	v, err := c.compileViews(tag)
+	c.tags.Store(tag, parsedViews{views: v, err: err})
+	return v, err
+}
+
+// fieldRule returns the rule masking a struct field in the view of the
+// walk, a nil rule means the field is zeroed. A field whose maskif tag does
+// not hold is masked as if it had no mask tag.
+func (m *Masker) fieldRule(s *maskState, field reflect.StructField) (rule, error) {
+	tag := m.fieldTag(s, field)
+	if raw, exist := field.Tag.Lookup(condTagName); exist {
+		c, err := s.cfg.parseCond(raw)
+		if err != nil {
+			return nil, err
+		}
+		ok, err := m.holds(s, c)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			tag = s.cfg.untaggedTag(field)
+		}
+	}
+
+	v, err := s.cfg.parseViews(tag)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.rule(s.view), nil
+}
+
+// compileRule splits a tag into alternatives separated by ?? and those into
+// masks separated by |. A tag which would leave a part empty, such as
+// `char,,|` masking with pipes, is taken as a single mask.
+func (c *config) compileRule(tag string) (rule, error) {
+	alternatives := splitTop(tag, "??")
+	r := make(rule, len(alternatives))
+	for i, alternative := range alternatives {
+		for _, raw := range splitTop(alternative, "|") {
+			raw = strings.TrimSpace(raw)
+			if len(raw) == 0 {
+				return c.compileSingle(tag)
+			}
+			st, err := c.parseTag(raw)
+			if err != nil {
+				return nil, err
+			}
+			r[i] = append(r[i], st)
+		}
+	}
+	if len(r) == 1 && len(r[0]) == 1 {
+		return c.compileSingle(tag)
+	}
+
+	return r, nil
+}
+
+// compileSingle parses the whole tag as a single mask
+func (c *config) compileSingle(tag string) (rule, error) {
+	if strings.TrimSpace(tag) == keepTag {
+		return keepRule, nil
+	}
+
+	st, err := c.parseTag(tag)
+	if err != nil {
+		return nil, err
+	}
+	return rule{{st}}, nil
+}
+
+// splitTop splits s by sep outside of quotes and parentheses
+func splitTop(s string, sep string) []string {
+	var (
+		parts []string
+		quote byte
+		depth int
+		start int
+	)
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case depth == 0 && strings.HasPrefix(s[i:], sep):
+			parts = append(parts, s[start:i])
+			start = i + len(sep)
+			i += len(sep) - 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// parseTag parses a single mask into its name followed by its positional
+// arguments, the arguments of masks with a schema are checked as well
+func (c *config) parseTag(tag string) ([]string, error) {
+	parsed, err := c.compileTag(tag)
+	if err != nil {
+		return nil, fmt.Errorf("%w: tag %q: %v", ErrInvalidArgument, tag, err)
+	}
+	if schema, exist := c.argSchemas[strings.TrimPrefix(parsed[0], eachPrefix)]; exist {
+		if _, err := schema.parse(parsed[1:]); err != nil {
+			return nil, fmt.Errorf("tag %q: %w", tag, err)
+		}
+	}
+	return parsed, nil
+}
+
+// compileTag understands two forms of tag:
+//
+//	name,arg,arg        the original form, arguments are kept as they are
+//	                    unless quoted, so `char,,','` masks with commas
+//	name(arg, key=arg)  arguments may be given by name and quoted with '
+//	                    or ", \ escapes the quote inside quoted arguments
+func (c *config) compileTag(tag string) ([]string, error) {
+	i := strings.IndexAny(tag, "(,")
+	if i < 0 {
+		return []string{strings.TrimSpace(tag)}, nil
+	}
+
+	name := strings.TrimSpace(tag[:i])
+	if tag[i] == ',' {
+		return append([]string{name}, splitArgs(tag[i+1:])...), nil
+	}
+
+	body := strings.TrimSpace(tag[i+1:])
+	if !strings.HasSuffix(body, ")") {
+		return nil, fmt.Errorf("missing )")
+	}
+	body = body[:len(body)-1]
+
+	parsed := []string{name}
+	named := false
+	sc := &tagScanner{s: body}
+	for sc.skipSpace(); !sc.done(); {
+		key := sc.key()
+		value, err := sc.value()
+		if err != nil {
+			return nil, err
+		}
+
+		if key == "" {
+			if named {
+				return nil, fmt.Errorf("positional argument %q after named argument", value)
+			}
+			parsed = append(parsed, value)
+		} else {
+			named = true
+			pos, exist := c.argPosition(strings.TrimPrefix(name, eachPrefix), key)
+			if !exist {
+				return nil, fmt.Errorf("%s has no argument named %s", name, key)
+			}
+			for len(parsed) <= pos+1 {
+				parsed = append(parsed, "")
+			}
+			parsed[pos+1] = value
+		}
+
+		sc.skipSpace()
+		if sc.done() {
+			break
+		}
+		if sc.next() != ',' {
+			return nil, fmt.Errorf("expect , at %d", sc.i-1)
+		}
+		sc.skipSpace()
+		if sc.done() {
+			return nil, fmt.Errorf("missing argument after ,")
+		}
+	}
+
+	return parsed, nil
+}
+
+// splitArgs splits the arguments of the original tag form by commas,
+// an argument wholly enclosed in quotes is unquoted
+func splitArgs(s string) []string {
+	var args []string
+	for {
+		if len(s) > 0 && (s[0] == '\'' || s[0] == '"') {
+			sc := &tagScanner{s: s}
+			if v, err := sc.quoted(); err == nil && (sc.done() || sc.peek() == ',') {
+				args = append(args, v)
+				if sc.done() {
+					return args
+				}
+				s = s[sc.i+1:]
+				continue
+			}
+		}
+
+		i := strings.IndexByte(s, ',')
+		if i < 0 {
+			return append(args, s)
+		}
+		args = append(args, s[:i])
+		s = s[i+1:]
+	}
+}
+
+type tagScanner struct {
+	s string
+	i int
+}
+
+func (sc *tagScanner) done() bool {
+	return sc.i >= len(sc.s)
+}
+
+func (sc *tagScanner) peek() byte {
+	return sc.s[sc.i]
+}
+
+func (sc *tagScanner) next() byte {
+	sc.i++
+	return sc.s[sc.i-1]
+}
+
+func (sc *tagScanner) skipSpace() {
+	for !sc.done() && (sc.peek() == ' ' || sc.peek() == '\t') {
+		sc.i++
+	}
+}
+
+// key consumes `name =` and returns the name, or nothing if the argument
+// is not named
+func (sc *tagScanner) key() string {
+	j := sc.i
+	for j < len(sc.s) && isIdent(sc.s[j], j == sc.i) {
+		j++
+	}
+	k := j
+	for k < len(sc.s) && (sc.s[k] == ' ' || sc.s[k] == '\t') {
+		k++
+	}
+	if j == sc.i || k >= len(sc.s) || sc.s[k] != '=' {
+		return ""
+	}
+
+	key := sc.s[sc.i:j]
+	sc.i = k + 1
+	sc.skipSpace()
+	return key
+}
+
+func isIdent(c byte, first bool) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || !first && '0' <= c && c <= '9'
+}
+
+// value consumes a quoted argument or a bare one ending at , or the end
+func (sc *tagScanner) value() (string, error) {
+	if !sc.done() && (sc.peek() == '\'' || sc.peek() == '"') {
+		return sc.quoted()
+	}
+
+	j := strings.IndexByte(sc.s[sc.i:], ',')
+	if j < 0 {
+		j = len(sc.s) - sc.i
+	}
+	v := strings.TrimSpace(sc.s[sc.i : sc.i+j])
+	sc.i += j
+	return v, nil
+}
+
+func (sc *tagScanner) quoted() (string, error) {
+	quote := sc.next()
+	var b strings.Builder
+	for !sc.done() {
+		c := sc.next()
+		switch {
+		case c == '\\' && !sc.done():
+			b.WriteByte(sc.next())
+		case c == quote:
+			return b.String(), nil
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", fmt.Errorf("unterminated %c", quote)
+}
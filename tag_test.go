@@ -0,0 +1,130 @@
+package gmask
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMasker_parseTag(t *testing.T) {
+	m := RegMaskArgsFunc(New(), MaskTypePartial, MaskPartialString, PartialArgs...).
+		RegMaskArgNames("custom", "a", "b|bee")
+
+	for tag, expected := range map[string][]string{
+		"":                            {""},
+		"zero":                        {"zero"},
+		"char,3":                      {"char", "3"},
+		"char,,-":                     {"char", "", "-"},
+		"char,, ":                     {"char", "", " "},
+		"char,,'":                     {"char", "", "'"},
+		"char,3,','":                  {"char", "3", ","},
+		`json,'a,b',"c"`:              {"json", "a,b", "c"},
+		"char()":                      {"char"},
+		"char(3)":                     {"char", "3"},
+		"char(len=3, with='-')":       {"char", "3", "-"},
+		"char( with = ',' )":          {"char", "", ","},
+		`char(with='\'')`:             {"char", "", "'"},
+		"each:char(with=x)":           {"each:char", "", "x"},
+		"rand(min=10, max=20)":        {"rand", "20", "10"},
+		"rand(len=12)":                {"rand", "12"},
+		"partial(last=2, first=1)":    {"partial", "1", "2"},
+		"custom(1, bee='x=y')":        {"custom", "1", "x=y"},
+		"hash(alg=md5)":               {"hash", "md5"},
+		"json('a(b)', 'c')":           {"json", "a(b)", "c"},
+		"custom(a=' padded ', b = b)": {"custom", " padded ", "b"},
+	} {
+		parsed, err := m.c().parseTag(tag)
+		assert.NoError(t, err, tag)
+		assert.Equal(t, expected, parsed, tag)
+	}
+
+	for _, tag := range []string{
+		"char(len=3",
+		"char(len=3,)",
+		"char(with='-)",
+		"char(len=3, '-')",
+		"char(size=3)",
+		"zero(x=1)",
+		"char('a' 'b')",
+	} {
+		_, err := m.c().parseTag(tag)
+		assert.ErrorIs(t, err, ErrInvalidArgument, tag)
+	}
+}
+
+func TestMasker_NamedArgs(t *testing.T) {
+	type card struct {
+		Number string `mask:"partial(last=4, with='#')"`
+		Holder string `mask:"char(len=3, with=',')"`
+		Bad    string `mask:"char(size=3)"`
+	}
+
+	masked, err := New().
+		RegMaskStringFunc(MaskTypeChar, MaskCharString()).
+		SetStrict(false).
+		SetFallback(MaskZero).
+		Mask(card{Number: "4111111111111111", Holder: "foo", Bad: "bar"})
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+	assert.Equal(t, card{Number: "4111111111111111", Holder: ",,,"}, masked)
+
+	type account struct {
+		Number string `mask:"partial(first=2, with='#')"`
+		Secret string `mask:"rand(len=3)"`
+	}
+	maskedAccount, err := Mask(account{Number: "12345678", Secret: "secret"})
+	assert.NoError(t, err)
+	assert.Equal(t, "12##5678", maskedAccount.Number)
+	assert.Len(t, maskedAccount.Secret, 3)
+}
+
+func TestMasker_compileRule(t *testing.T) {
+	m := New()
+	for tag, expected := range map[string]rule{
+		"char,3":                {{{"char", "3"}}},
+		"char,,|":               {{{"char", "", "|"}}},
+		"char,,?":               {{{"char", "", "?"}}},
+		"char(with='|')":        {{{"char", "", "|"}}},
+		"char,3,'|'":            {{{"char", "3", "|"}}},
+		"partial|hash":          {{{"partial"}, {"hash"}}},
+		"pan ?? char":           {{{"pan"}}, {{"char"}}},
+		"a|b,md5 ?? c(3) ?? d":  {{{"a"}, {"b", "md5"}}, {{"c", "3"}}, {{"d"}}},
+		"json('a|b') | hash":    {{{"json", "a|b"}, {"hash"}}},
+		"each:char,1 ?? each:z": {{{"each:char", "1"}}, {{"each:z"}}},
+	} {
+		r, err := m.c().compileRule(tag)
+		assert.NoError(t, err, tag)
+		assert.Equal(t, expected, r, tag)
+	}
+}
+
+func TestMasker_Pipeline(t *testing.T) {
+	type secret struct {
+		Hashed   string `mask:"partial|hash,md5"`
+		Fallback string `mask:"hash,sha512 ?? char,3"`
+		Failed   string `mask:"hash,sha512 ?? char,x"`
+		Number   int8   `mask:"rand,10,5 | rand,5"`
+	}
+	demo := secret{Hashed: "4111111111111111", Fallback: "secret", Failed: "secret", Number: 100}
+
+	partial, _ := Default().String("4111111111111111", MaskTypePartial)
+	hashed, _ := MaskHashString(partial, "md5")
+
+	masked, err := NewWithDefaults().SetFallback(MaskRedacted).Mask(demo)
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+	s := masked.(secret)
+	assert.Equal(t, hashed, s.Hashed)
+	assert.Equal(t, "***", s.Fallback)
+	assert.Equal(t, Redacted, s.Failed)
+	assert.True(t, 0 <= s.Number && s.Number < 5)
+
+	_, err = Mask(demo)
+	assert.Error(t, err)
+
+	err = Default().MaskInPlace(&demo)
+	assert.Error(t, err)
+	assert.Equal(t, hashed, demo.Hashed)
+	assert.Equal(t, "***", demo.Fallback)
+	assert.Equal(t, "secret", demo.Failed)
+
+	err = Default().Validate(secret{})
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+}
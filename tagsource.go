@@ -0,0 +1,58 @@
+package gmask
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// tagSource maps the values of a struct tag other than the mask tag to rules
+type tagSource struct {
+	name  string
+	rules map[string]string
+}
+
+// RegTagSource reads the struct tags named name as well, for fields without
+// mask tag. rules maps the values of the tag to rules written like mask
+// tags, values not in rules are ignored, an empty value matches a tag given
+// without value. Sources are tried in the order they are registered,
+// registering a name again replaces its rules. RegTagSource panics if a
+// rule can not be parsed.
+//
+// Example: RegTagSource("log", map[string]string{"-": "zero"})
+func (m *Masker) RegTagSource(name string, rules map[string]string) *Masker {
+	for value, rule := range rules {
+		if _, err := m.c().parseViews(rule); err != nil {
+			panic(fmt.Sprintf("gmask: rule of `%s:%q`: %v", name, value, err))
+		}
+	}
+
+	source := tagSource{name: name, rules: cloneMap(rules)}
+	return m.update(func(c *config) {
+		sources := make([]tagSource, 0, len(c.tagSources)+1)
+		replaced := false
+		for _, s := range c.tagSources {
+			if s.name == name {
+				s, replaced = source, true
+			}
+			sources = append(sources, s)
+		}
+		if !replaced {
+			sources = append(sources, source)
+		}
+		c.tagSources = sources
+	})
+}
+
+// sourceTag returns the rule the tag sources give to a field
+func (c *config) sourceTag(field reflect.StructField) (string, bool) {
+	for _, s := range c.tagSources {
+		value, exist := field.Tag.Lookup(s.name)
+		if !exist {
+			continue
+		}
+		if rule, exist := s.rules[value]; exist {
+			return rule, true
+		}
+	}
+	return "", false
+}
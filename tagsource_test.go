@@ -0,0 +1,67 @@
+package gmask
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMasker_RegTagSource(t *testing.T) {
+	type account struct {
+		Name     string
+		Password string `sensitive:"true"`
+		Debug    string `log:"-"`
+		Note     string `redact:""`
+		Internal string `json:"-"`
+		Email    string `json:"email" sensitive:"false"`
+		Token    string `log:"-" mask:"char,2"`
+		Secret   string `log:"-" sensitive:"true"`
+	}
+	demo := account{
+		Name:     "foo",
+		Password: "bar",
+		Debug:    "debug",
+		Note:     "note",
+		Internal: "internal",
+		Email:    "foo@bar.com",
+		Token:    "token",
+		Secret:   "secret",
+	}
+	expected := account{
+		Name:     "foo",
+		Password: "***",
+		Note:     Redacted,
+		Email:    "foo@bar.com",
+		Token:    "**",
+		Secret:   "***",
+	}
+
+	m := New(WithDefaults(), WithTagSource("sensitive", map[string]string{"true": "char,3"})).
+		RegTagSource("log", map[string]string{"-": "zero"}).
+		RegTagSource("redact", map[string]string{"": MaskTypeRedact}).
+		RegTagSource("json", map[string]string{"-": MaskTypeOmit})
+	masked, err := m.Mask(demo)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, masked)
+	assert.NoError(t, m.Validate(account{}))
+
+	// registering a source again replaces its rules, clones keep theirs
+	clone := m.Clone()
+	m.RegTagSource("sensitive", map[string]string{"true": "char,1"})
+	masked, err = m.Mask(demo)
+	assert.NoError(t, err)
+	assert.Equal(t, "*", masked.(account).Password)
+	assert.Equal(t, "*", masked.(account).Secret)
+	masked, err = clone.Mask(demo)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, masked)
+
+	err = m.MaskInPlace(&demo)
+	assert.NoError(t, err)
+	assert.Equal(t, "", demo.Debug)
+	assert.Equal(t, "*", demo.Password)
+
+	m.RegTagSource("log", map[string]string{"-": "nope"})
+	assert.ErrorIs(t, m.Validate(account{}), ErrUnknownStrategy)
+	assert.Panics(t, func() { m.RegTagSource("log", map[string]string{"-": "char("}) })
+}
@@ -0,0 +1,139 @@
+package gmask
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Validate checks every mask tag of a type once and reports all problems
+// found joined together, such as unknown mask names, invalid arguments and
+// masks that can not mask the kind of their field. v is either a
+// reflect.Type or a sample value of the type.
+//
+// Arguments are checked by running each mask once on the zero value of its
+// field, a mask panicking is reported as well. Field masks, which may have
+// side effects, and predicates are only checked by name. Unknown names are
+// reported even if the masker is not strict.
+func (m *Masker) Validate(v any) error {
+	rt, ok := v.(reflect.Type)
+	if !ok {
+		rt = reflect.TypeOf(v)
+	}
+	if rt == nil {
+		return fmt.Errorf("%w: can not validate nil", ErrInvalidArgument)
+	}
+
+	strict := m.Clone().SetStrict(true).SetFallback(nil)
+	s := strict.newState(context.Background(), rt)
+	strict.validate(s, make(map[visitKey]bool), rt)
+
+	return errors.Join(s.errs...)
+}
+
+func (m *Masker) validate(s *maskState, seen map[visitKey]bool, rt reflect.Type, tag ...string) {
+	key := visitKey{typ: rt, tag: strings.Join(tag, ",")}
+	if seen[key] {
+		return
+	}
+	seen[key] = true
+	tag, _ = s.cfg.denyTag(rt, tag)
+
+	tagged := len(tag) != 0 && len(tag[0]) != 0
+	switch rt.Kind() {
+	case reflect.Ptr:
+		m.validate(s, seen, rt.Elem(), tag...)
+	case reflect.Interface:
+		// the kind is only known when masking, check the name at least
+		if tagged && tag[0] != MaskTypePublic && !s.cfg.registered(strings.TrimPrefix(tag[0], eachPrefix)) {
+			s.errs = append(s.errs, s.wrap(s.cfg.missing(tag[0], rt.String()), tag))
+		}
+	case reflect.Struct:
+		if tagged {
+			m.dryRun(s, rt, tag)
+		}
+		defer s.enterPolicy(rt)()
+		for i := 0; i < rt.NumField(); i++ {
+			field := rt.Field(i)
+			// skip private field
+			if field.PkgPath != "" {
+				continue
+			}
+
+			s.push(field.Name)
+			s.parent, s.field = reflect.New(rt).Elem(), field
+			// a field whose maskif tag does not hold is masked as untagged
+			tags := []string{m.fieldTag(s, field)}
+			if tag, exist := field.Tag.Lookup(condTagName); exist {
+				if err := s.cfg.validateCond(rt, tag); err != nil {
+					s.errs = append(s.errs, s.wrap(err, tags))
+				}
+				tags = append(tags, s.cfg.untaggedTag(field))
+			}
+			for _, tag := range tags {
+				v, err := s.cfg.parseViews(tag)
+				if err != nil {
+					s.errs = append(s.errs, s.wrap(err, []string{tag}))
+					continue
+				}
+				for _, r := range v.rules() {
+					for _, p := range r {
+						for _, st := range p {
+							m.validate(s, seen, field.Type, st...)
+						}
+					}
+				}
+			}
+			s.pop()
+		}
+	case reflect.Slice, reflect.Array, reflect.Map:
+		if tagged && !isEachTag(tag) {
+			bytes := rt.Kind() == reflect.Slice && rt.Elem().Kind() == reflect.Uint8
+			if bytes || has(s.cfg.maskAnyFuncMap, tag[0]) || has(s.cfg.maskFieldFuncMap, tag[0]) {
+				m.dryRun(s, rt, tag)
+				return
+			}
+		}
+
+		s.push("[*]")
+		m.validate(s, seen, rt.Elem(), eachTag(tag)...)
+		if rt.Kind() == reflect.Map && s.cfg.masksKeys(rt, eachTag(tag)) {
+			m.validate(s, seen, rt.Key())
+		}
+		s.pop()
+	default:
+		if tagged {
+			m.dryRun(s, rt, tag)
+		}
+	}
+}
+
+// dryRun masks an empty value of rt with the tag and records the failure
+func (m *Masker) dryRun(s *maskState, rt reflect.Type, tag []string) {
+	if has(s.cfg.maskFieldFuncMap, tag[0]) {
+		return
+	}
+	depth := len(s.path)
+	defer func() {
+		if r := recover(); r != nil {
+			s.path = s.path[:depth]
+			s.errs = append(s.errs, s.wrap(fmt.Errorf("%w: %s panics: %v", ErrInvalidArgument, tag[0], r), tag))
+		}
+	}()
+
+	var rv reflect.Value
+	switch rt.Kind() {
+	case reflect.Slice:
+		rv = reflect.MakeSlice(rt, 0, 0)
+	case reflect.Map:
+		rv = reflect.MakeMap(rt)
+	default:
+		rv = reflect.New(rt).Elem()
+	}
+
+	if _, err := m.mask(s, rv, reflect.Value{}, tag...); err != nil {
+		s.errs = append(s.errs, err)
+	}
+}
@@ -0,0 +1,82 @@
+package gmask
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+)
+
+func TestMasker_Validate(t *testing.T) {
+	type card struct {
+		Number string `mask:"char,x"`
+		Holder string `mask:"char,-1,-"`
+	}
+	type user struct {
+		Name     string          `mask:"hsah"`
+		Age      int             `mask:"hash"`
+		Password string          `mask:"hash,sha512"`
+		Token    []byte          `mask:"rand,abc"`
+		Raw      json.RawMessage `mask:"json,password"`
+		Cards    []card
+		Friends  []*user           `mask:"zero"`
+		Tags     map[string]string `mask:"each:char,3"`
+		Any      any               `mask:"nope"`
+		Next     *user
+	}
+
+	err := Default().Validate(user{})
+	assert.Error(t, err)
+
+	var paths []string
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var maskErr *MaskError
+		assert.True(t, errors.As(e, &maskErr))
+		paths = append(paths, maskErr.Path)
+	}
+	assert.Equal(t, []string{
+		"user.Name",
+		"user.Age",
+		"user.Password",
+		"user.Token",
+		"user.Cards[*].Number",
+		"user.Any",
+	}, paths)
+	assert.ErrorIs(t, err, ErrUnknownStrategy)
+	assert.ErrorIs(t, err, ErrUnsupportedKind)
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+
+	// a reflect.Type works the same and lenient maskers still report unknown names
+	err = New().SetStrict(false).Validate(reflect.TypeOf(&card{}))
+	assert.ErrorIs(t, err, ErrUnknownStrategy)
+
+	assert.NoError(t, Default().Validate(testStruct{}))
+	assert.Error(t, Default().Validate(nil))
+}
+
+func TestMasker_ValidateDryRun(t *testing.T) {
+	type secret struct {
+		Key   string `mask:"boom,3"`
+		Vault string `mask:"vault"`
+		Note  string `mask:"char,-2"`
+	}
+
+	calls := 0
+	m := NewWithDefaults().
+		RegMaskStringFunc("boom", func(value string, arg ...string) (string, error) {
+			panic("index out of range")
+		}).
+		RegMaskFieldFunc("vault", func(fc FieldContext, value any, arg ...string) (any, error) {
+			calls++
+			return value, nil
+		})
+
+	// a panicking mask is reported and field masks are never called
+	err := m.Validate(secret{})
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+	assert.ErrorContains(t, err, "secret.Key")
+	assert.ErrorContains(t, err, "boom panics")
+	assert.ErrorContains(t, err, "secret.Note")
+	assert.Zero(t, calls)
+}
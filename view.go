@@ -0,0 +1,132 @@
+package gmask
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// keepTag leaves a value as it is, such as `mask:"audit=-"` showing the
+// value unmasked to the audit view
+const keepTag = "-"
+
+// keepRule is the rule of keepTag, it masks nothing
+var keepRule = rule{{{MaskTypePublic}}}
+
+// views is a parsed tag, holding a rule per view and the default rule
+//
+// Example: `mask:"support=partial,0,4;partner=zero;audit=-"`
+type views struct {
+	// byName is nil for a tag without views
+	byName map[string]rule
+	// fallback is the rule of the part without a view name,
+	// nil when there is no such part
+	fallback rule
+}
+
+// rule returns the rule of the view, views missing from the tag use the
+// part without a view name, or zero the value without such a part
+func (v *views) rule(view string) rule {
+	if r, exist := v.byName[view]; exist {
+		return r
+	}
+	return v.fallback
+}
+
+// rules returns every rule of the tag
+func (v *views) rules() []rule {
+	var rules []rule
+	if v.fallback != nil {
+		rules = append(rules, v.fallback)
+	}
+	for _, r := range v.byName {
+		rules = append(rules, r)
+	}
+	return rules
+}
+
+// compileViews splits a tag into views separated by ;, each one prefixed
+// with the view name and =. A tag which would leave a part empty, such as
+// `char,,;` masking with semicolons, is taken as a single rule.
+func (c *config) compileViews(tag string) (*views, error) {
+	parts := splitTop(tag, ";")
+	if len(parts) == 1 && viewName(tag) == "" {
+		r, err := c.compileRule(tag)
+		if err != nil {
+			return nil, err
+		}
+		return &views{fallback: r}, nil
+	}
+
+	v := &views{byName: make(map[string]rule, len(parts))}
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if len(part) == 0 {
+			r, err := c.compileSingle(tag)
+			if err != nil {
+				return nil, err
+			}
+			return &views{fallback: r}, nil
+		}
+
+		name := viewName(part)
+		if name != "" {
+			part = part[strings.IndexByte(part, '=')+1:]
+		}
+		r, err := c.compileRule(part)
+		if err != nil {
+			return nil, err
+		}
+
+		if name == "" {
+			if v.fallback != nil {
+				return nil, fmt.Errorf("%w: tag %q: more than one part without view", ErrInvalidArgument, tag)
+			}
+			v.fallback = r
+		} else if _, exist := v.byName[name]; exist {
+			return nil, fmt.Errorf("%w: tag %q: view %s given twice", ErrInvalidArgument, tag, name)
+		} else {
+			v.byName[name] = r
+		}
+	}
+
+	return v, nil
+}
+
+// viewName returns the view name a part of a tag starts with, if any
+func viewName(part string) string {
+	part = strings.TrimSpace(part)
+	i := 0
+	for i < len(part) && (isIdent(part[i], i == 0) || i > 0 && part[i] == '-') {
+		i++
+	}
+	if i == 0 || i >= len(part) || part[i] != '=' {
+		return ""
+	}
+	return part[:i]
+}
+
+type viewKey struct{}
+
+// WithView returns a copy of ctx selecting the view of MaskContext
+func WithView(ctx context.Context, view string) context.Context {
+	return context.WithValue(ctx, viewKey{}, view)
+}
+
+// ViewFromContext returns the view selected by WithView
+func ViewFromContext(ctx context.Context) string {
+	view, _ := ctx.Value(viewKey{}).(string)
+	return view
+}
+
+// MaskFor masks target for the audience named view. A tag may give a rule
+// per view separated by ;, the part without a view name applies to views
+// missing from the tag and to Mask, without such a part those views get
+// the zero value.
+//
+// Example: `mask:"support=partial,0,4;partner=zero;audit=-"`
+// shows the last 4 chars to support, nothing to partner and the whole value
+// to audit, other views get the zero value.
+func (m *Masker) MaskFor(view string, target any) (ret any, err error) {
+	return m.MaskContext(WithView(context.Background(), view), target)
+}
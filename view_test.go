@@ -0,0 +1,65 @@
+package gmask
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMasker_MaskFor(t *testing.T) {
+	type card struct {
+		Number string `mask:"support=partial,0,4;partner=zero;audit=-"`
+		Holder string `mask:"char,3;audit=-"`
+		Token  string `mask:"char,,;"`
+	}
+	demo := card{Number: "4111111111111111", Holder: "foo", Token: "token"}
+
+	for view, expected := range map[string]card{
+		"support": {Number: "************1111", Holder: "***", Token: ";;;;;;;;"},
+		"partner": {Number: "", Holder: "***", Token: ";;;;;;;;"},
+		"audit":   {Number: "4111111111111111", Holder: "foo", Token: ";;;;;;;;"},
+		"other":   {Number: "", Holder: "***", Token: ";;;;;;;;"},
+		"":        {Number: "", Holder: "***", Token: ";;;;;;;;"},
+	} {
+		masked, err := Default().MaskFor(view, demo)
+		assert.NoError(t, err, view)
+		assert.Equal(t, expected, masked, view)
+
+		masked, err = Default().MaskContext(WithView(context.Background(), view), demo)
+		assert.NoError(t, err, view)
+		assert.Equal(t, expected, masked, view)
+	}
+
+	masked, err := Default().Mask(demo)
+	assert.NoError(t, err)
+	assert.Equal(t, card{Holder: "***", Token: ";;;;;;;;"}, masked)
+
+	assert.Equal(t, "audit", ViewFromContext(WithView(context.Background(), "audit")))
+	assert.NoError(t, Default().Validate(card{}))
+}
+
+func TestMasker_compileViews(t *testing.T) {
+	m := New()
+	for tag, expected := range map[string]*views{
+		"char,3":      {fallback: rule{{{"char", "3"}}}},
+		"-":           {fallback: rule{{{MaskTypePublic}}}},
+		"char,,=":     {fallback: rule{{{"char", "", "="}}}},
+		"char(len=3)": {fallback: rule{{{"char", "3"}}}},
+		"char,,;":     {fallback: rule{{{"char", "", ";"}}}},
+		"audit=-":     {byName: map[string]rule{"audit": {{{MaskTypePublic}}}}},
+		"a=zero; b-c=char,3 ; hash": {
+			byName:   map[string]rule{"a": {{{"zero"}}}, "b-c": {{{"char", "3"}}}},
+			fallback: rule{{{"hash"}}},
+		},
+	} {
+		v, err := m.c().compileViews(tag)
+		assert.NoError(t, err, tag)
+		assert.Equal(t, expected, v, tag)
+	}
+
+	for _, tag := range []string{"a=zero;a=char", "zero;char", "a=char(;b=zero"} {
+		_, err := m.c().compileViews(tag)
+		assert.ErrorIs(t, err, ErrInvalidArgument, tag)
+	}
+}